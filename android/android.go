@@ -0,0 +1,432 @@
+// Package android reads and writes Android string resources, the
+// res/values/strings.xml files of an app, and maps Lingotek locales to
+// resource directories.
+//
+// Values are kept as the raw XML between the tags, so inline markup such
+// as <b> or <xliff:g> and Android's backslash escapes survive a round
+// trip. Use Text to get the plain text of a value and Escape to build one.
+package android
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	lingotek "github.com/CuriousLLC/Lingotek"
+)
+
+// Format is the Lingotek format name of Android string resources.
+const Format = "ANDROID_STRINGS"
+
+var NotResources = errors.New("Not an Android resources file")
+
+type Kind int
+
+const (
+	String Kind = iota
+	StringArray
+	Plurals
+)
+
+var kindNames = map[string]Kind{
+	"string":       String,
+	"string-array": StringArray,
+	"plurals":      Plurals,
+}
+
+// Quantities are the plural quantities Android knows, in the order they
+// are written.
+var Quantities = []string{"zero", "one", "two", "few", "many", "other"}
+
+// Resources is a parsed strings.xml.
+type Resources struct {
+	// Attrs are the attributes of <resources>, namespace declarations
+	// included.
+	Attrs     []xml.Attr
+	Resources []*Resource
+}
+
+// Resource is a <string>, <string-array> or <plurals>.
+type Resource struct {
+	Kind Kind
+	Name string
+	// Comment is the text of the XML comment just before the resource.
+	Comment string
+	// Attrs are the attributes besides name, e.g. translatable.
+	Attrs []xml.Attr
+	// Value is the content of a String.
+	Value string
+	// Items are the items of a StringArray.
+	Items []string
+	// Quantities maps the quantities of Plurals to their content.
+	Quantities map[string]string
+}
+
+// Translatable is false for resources marked translatable="false".
+func (r *Resource) Translatable() bool {
+	for _, attr := range r.Attrs {
+		if attr.Name.Local == "translatable" {
+			return attr.Value != "false"
+		}
+	}
+
+	return true
+}
+
+// Unit is a single translatable string of a resources file. Array items
+// are keyed as "name[0]" and plurals as "name[one]".
+type Unit struct {
+	Key     string
+	Value   string
+	Comment string
+}
+
+// Specifiers returns the format specifiers of the unit's text.
+func (u *Unit) Specifiers() []string {
+	return FormatSpecifiers(Text(u.Value))
+}
+
+// Units returns the translatable strings of the file in order.
+func (r *Resources) Units() []Unit {
+	var units []Unit
+
+	for _, resource := range r.Resources {
+		if !resource.Translatable() {
+			continue
+		}
+
+		switch resource.Kind {
+		case String:
+			units = append(units, Unit{resource.Name, resource.Value, resource.Comment})
+		case StringArray:
+			for i, item := range resource.Items {
+				key := resource.Name + "[" + strconv.Itoa(i) + "]"
+				units = append(units, Unit{key, item, resource.Comment})
+			}
+		case Plurals:
+			for _, quantity := range Quantities {
+				if value, ok := resource.Quantities[quantity]; ok {
+					units = append(units, Unit{resource.Name + "[" + quantity + "]", value, resource.Comment})
+				}
+			}
+		}
+	}
+
+	return units
+}
+
+// Find returns the resource with the given name, or nil.
+func (r *Resources) Find(name string) *Resource {
+	for _, resource := range r.Resources {
+		if resource.Name == name {
+			return resource
+		}
+	}
+
+	return nil
+}
+
+type element struct {
+	Attrs []xml.Attr `xml:",any,attr"`
+	Inner string     `xml:",innerxml"`
+	Items []struct {
+		Quantity string `xml:"quantity,attr"`
+		Inner    string `xml:",innerxml"`
+	} `xml:"item"`
+}
+
+// Parse reads a strings.xml file. Elements other than strings, string
+// arrays and plurals are skipped.
+func Parse(r io.Reader) (*Resources, error) {
+	decoder := xml.NewDecoder(r)
+	var resources *Resources
+	comment := ""
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch token := token.(type) {
+		case xml.Comment:
+			comment = strings.TrimSpace(string(token))
+		case xml.StartElement:
+			if resources == nil {
+				if token.Name.Local != "resources" {
+					return nil, NotResources
+				}
+				resources = &Resources{Attrs: token.Attr}
+				// A comment before the root, such as a license header,
+				// doesn't describe the first resource
+				comment = ""
+				continue
+			}
+
+			kind, ok := kindNames[token.Name.Local]
+			if !ok {
+				decoder.Skip()
+				comment = ""
+				continue
+			}
+
+			var e element
+			err = decoder.DecodeElement(&e, &token)
+			if err != nil {
+				return nil, err
+			}
+
+			resource := Resource{Kind: kind, Comment: comment}
+			for _, attr := range e.Attrs {
+				if attr.Name.Local == "name" && attr.Name.Space == "" {
+					resource.Name = attr.Value
+				} else {
+					resource.Attrs = append(resource.Attrs, attr)
+				}
+			}
+
+			switch kind {
+			case String:
+				resource.Value = e.Inner
+			case StringArray:
+				for _, item := range e.Items {
+					resource.Items = append(resource.Items, item.Inner)
+				}
+			case Plurals:
+				resource.Quantities = make(map[string]string)
+				for _, item := range e.Items {
+					resource.Quantities[item.Quantity] = item.Inner
+				}
+			}
+
+			resources.Resources = append(resources.Resources, &resource)
+			comment = ""
+		case xml.CharData:
+			// Only a blank line separates a comment from the next resource
+			if strings.Count(string(token), "\n") > 1 {
+				comment = ""
+			}
+		}
+	}
+
+	if resources == nil {
+		return nil, NotResources
+	}
+
+	return resources, nil
+}
+
+// Write writes the file, indented the way Android Studio does.
+func (r *Resources) Write(w io.Writer) error {
+	var buf bytes.Buffer
+	prefixes := namespacePrefixes(r.Attrs)
+
+	buf.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<resources")
+	writeAttrs(&buf, r.Attrs, prefixes)
+	buf.WriteString(">\n")
+
+	for _, resource := range r.Resources {
+		if resource.Comment != "" {
+			buf.WriteString("    <!-- " + resource.Comment + " -->\n")
+		}
+
+		tag := "string"
+		switch resource.Kind {
+		case StringArray:
+			tag = "string-array"
+		case Plurals:
+			tag = "plurals"
+		}
+
+		buf.WriteString("    <" + tag + ` name="`)
+		xml.EscapeText(&buf, []byte(resource.Name))
+		buf.WriteString(`"`)
+		writeAttrs(&buf, resource.Attrs, prefixes)
+		buf.WriteString(">")
+
+		switch resource.Kind {
+		case String:
+			buf.WriteString(resource.Value)
+		case StringArray:
+			buf.WriteString("\n")
+			for _, item := range resource.Items {
+				buf.WriteString("        <item>" + item + "</item>\n")
+			}
+			buf.WriteString("    ")
+		case Plurals:
+			buf.WriteString("\n")
+			for _, quantity := range Quantities {
+				if value, ok := resource.Quantities[quantity]; ok {
+					buf.WriteString(`        <item quantity="` + quantity + `">` + value + "</item>\n")
+				}
+			}
+			buf.WriteString("    ")
+		}
+
+		buf.WriteString("</" + tag + ">\n")
+	}
+
+	buf.WriteString("</resources>\n")
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// namespacePrefixes maps the namespaces declared by attrs to their
+// prefixes. The decoder replaces prefixes by namespace URLs, so they are
+// needed to write prefixed attributes back.
+func namespacePrefixes(attrs []xml.Attr) map[string]string {
+	prefixes := make(map[string]string)
+	for _, attr := range attrs {
+		if attr.Name.Space == "xmlns" {
+			prefixes[attr.Value] = attr.Name.Local
+		}
+	}
+
+	return prefixes
+}
+
+func writeAttrs(buf *bytes.Buffer, attrs []xml.Attr, prefixes map[string]string) {
+	for _, attr := range attrs {
+		name := attr.Name.Local
+		if attr.Name.Space != "" {
+			prefix, ok := prefixes[attr.Name.Space]
+			if !ok {
+				prefix = attr.Name.Space
+			}
+			name = prefix + ":" + name
+		}
+
+		buf.WriteString(" " + name + `="`)
+		xml.EscapeText(buf, []byte(attr.Value))
+		buf.WriteString(`"`)
+	}
+}
+
+// UploadRequest returns a request uploading the translatable resources
+// to a project as a document in the given source locale.
+func (r *Resources) UploadRequest(title, localeCode, projectId string) (lingotek.UploadRequest, error) {
+	upload := Resources{Attrs: r.Attrs}
+	for _, resource := range r.Resources {
+		if resource.Translatable() {
+			upload.Resources = append(upload.Resources, resource)
+		}
+	}
+
+	var buf bytes.Buffer
+	err := upload.Write(&buf)
+	if err != nil {
+		return lingotek.UploadRequest{}, err
+	}
+
+	return lingotek.UploadRequest{
+		Title:      title,
+		LocaleCode: localeCode,
+		ProjectId:  projectId,
+		Format:     Format,
+		Charset:    "UTF-8",
+		Filename:   "strings.xml",
+		Reader:     &buf,
+	}, nil
+}
+
+// Dir returns the resource directory of a Lingotek locale code such as
+// "es-ES", e.g. "values-es-rES". Codes with a script use the BCP 47 form,
+// e.g. "values-b+sr+Latn".
+func Dir(localeCode string) string {
+	subtags := strings.Split(lingotek.CanonicalTag(localeCode), "-")
+	if len(subtags) == 1 {
+		return "values-" + subtags[0]
+	}
+
+	if len(subtags) == 2 && len(subtags[1]) == 2 {
+		return "values-" + subtags[0] + "-r" + subtags[1]
+	}
+
+	return "values-b+" + strings.Join(subtags, "+")
+}
+
+// WriteFile writes the resources as name, e.g. "strings.xml", in the
+// locale's directory below res.
+func (r *Resources) WriteFile(res, localeCode, name string) error {
+	dir := filepath.Join(res, Dir(localeCode))
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	err = r.Write(&buf)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0644)
+}
+
+var unescaper = strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\'`, "'", `\"`, `"`, `\@`, "@", `\?`, "?", `\\`, `\`)
+
+// Text returns the plain text of a value: markup is stripped, and quotes
+// and backslash escapes are resolved.
+func Text(value string) string {
+	var text strings.Builder
+	decoder := xml.NewDecoder(strings.NewReader("<x>" + value + "</x>"))
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			text.Reset()
+			text.WriteString(value)
+			break
+		}
+
+		if data, ok := token.(xml.CharData); ok {
+			text.Write(data)
+		}
+	}
+
+	s := text.String()
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+
+	return unescaper.Replace(s)
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\t", `\t`, "'", `\'`, `"`, `\"`, "&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Escape turns plain text into a value.
+func Escape(text string) string {
+	value := escaper.Replace(text)
+	if strings.HasPrefix(value, "@") || strings.HasPrefix(value, "?") {
+		value = `\` + value
+	}
+
+	return value
+}
+
+var specifier = regexp.MustCompile(`%(\d+\$)?[-#+ 0,(<]*\d*(\.\d+)?([tT][a-zA-Z]|[a-zA-Z%])`)
+
+// FormatSpecifiers returns the java.util.Formatter specifiers in text,
+// such as "%s" or "%1$d", in order. "%%" and "%n" aren't included.
+func FormatSpecifiers(text string) []string {
+	var specifiers []string
+	for _, match := range specifier.FindAllString(text, -1) {
+		if match != "%%" && match != "%n" {
+			specifiers = append(specifiers, match)
+		}
+	}
+
+	return specifiers
+}
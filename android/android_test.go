@@ -0,0 +1,166 @@
+package android
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func parseFile(t *testing.T, path string) *Resources {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	resources, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return resources
+}
+
+func TestParse(t *testing.T) {
+	resources := parseFile(t, "../test_data/android_strings.xml")
+
+	if len(resources.Resources) != 5 {
+		t.Fatalf("Expected 5 resources, got %d", len(resources.Resources))
+	}
+	if resources.Find("app_name").Translatable() {
+		t.Errorf("Expected app_name not to be translatable")
+	}
+
+	welcome := resources.Find("welcome")
+	if welcome.Comment != "Greets the signed in user" {
+		t.Errorf("Unexpected comment %q", welcome.Comment)
+	}
+	if text := Text(welcome.Value); text != "Welcome, %1$s!" {
+		t.Errorf("Unexpected text %q", text)
+	}
+
+	if text := Text(resources.Find("quote").Value); text != `Don't say "never" - 100%% sure` {
+		t.Errorf("Unexpected text %q", text)
+	}
+
+	expected := []Unit{
+		{"welcome", `Welcome, <b><xliff:g id="name">%1$s</xliff:g></b>!`, "Greets the signed in user"},
+		{"quote", `Don\'t say \"never\" - 100%% sure`, ""},
+		{"planets[0]", "Mercury", ""},
+		{"planets[1]", "Venus", ""},
+		{"songs[one]", "%d song found", ""},
+		{"songs[other]", "%d songs found", ""},
+	}
+	if units := resources.Units(); !reflect.DeepEqual(units, expected) {
+		t.Errorf("Expected units\n%v\ngot\n%v", expected, units)
+	}
+
+	if specifiers := expected[0].Specifiers(); !reflect.DeepEqual(specifiers, []string{"%1$s"}) {
+		t.Errorf("Unexpected specifiers %v", specifiers)
+	}
+	if specifiers := expected[1].Specifiers(); specifiers != nil {
+		t.Errorf("Expected %%%% not to be a specifier, got %v", specifiers)
+	}
+}
+
+func TestParseHeaderComment(t *testing.T) {
+	data := `<?xml version="1.0" encoding="utf-8"?>
+<!-- Licensed under the Apache License, Version 2.0 -->
+<resources>
+    <string name="title">Title</string>
+</resources>
+`
+
+	resources, err := Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if comment := resources.Find("title").Comment; comment != "" {
+		t.Errorf("Expected the header comment to be dropped, got %q", comment)
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	original, err := os.ReadFile("../test_data/android_strings.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resources, err := Parse(bytes.NewReader(original))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := resources.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != string(original) {
+		t.Errorf("Expected\n%s\ngot\n%s", original, buf.String())
+	}
+}
+
+func TestUploadRequest(t *testing.T) {
+	resources := parseFile(t, "../test_data/android_strings.xml")
+
+	upload, err := resources.UploadRequest("strings.xml", "en-US", "project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upload.Format != Format || upload.Filename != "strings.xml" {
+		t.Errorf("Unexpected upload %+v", upload)
+	}
+
+	content, err := io.ReadAll(upload.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uploaded, err := Parse(bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uploaded.Resources) != 4 || uploaded.Find("app_name") != nil {
+		t.Errorf("Expected untranslatable strings to be left out, got %d resources", len(uploaded.Resources))
+	}
+}
+
+func TestDir(t *testing.T) {
+	tests := map[string]string{
+		"es-ES":      "values-es-rES",
+		"fr":         "values-fr",
+		"pt_BR":      "values-pt-rBR",
+		"sr-Latn-RS": "values-b+sr+Latn+RS",
+		"es-419":     "values-b+es+419",
+	}
+
+	for code, expected := range tests {
+		if dir := Dir(code); dir != expected {
+			t.Errorf("%s: expected %s, got %s", code, expected, dir)
+		}
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	res := t.TempDir()
+	resources := Resources{Resources: []*Resource{{Kind: String, Name: "hello", Value: Escape("¡Hola, \"amigo\"!")}}}
+
+	if err := resources.WriteFile(res, "es-ES", "strings.xml"); err != nil {
+		t.Fatal(err)
+	}
+
+	written := parseFile(t, filepath.Join(res, "values-es-rES", "strings.xml"))
+	if text := Text(written.Find("hello").Value); text != "¡Hola, \"amigo\"!" {
+		t.Errorf("Unexpected text %q", text)
+	}
+
+	if value := Escape("@string/other & <more>"); value != `\@string/other &amp; &lt;more&gt;` {
+		t.Errorf("Unexpected escaped value %q", value)
+	}
+}
@@ -0,0 +1,260 @@
+package lingotek
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var ErrNoToken = errors.New("Token endpoint returned no access token")
+
+// expiryDelta refreshes tokens slightly early, so a token doesn't expire
+// while a request is on the wire.
+const expiryDelta = 10 * time.Second
+
+// tokenTimeout bounds a token request, so a hung token endpoint doesn't
+// stall every API call waiting for a token.
+const tokenTimeout = 30 * time.Second
+
+// Token is an OAuth2 bearer token.
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type"`
+	RefreshToken string    `json:"refresh_token"`
+	Expiry       time.Time `json:"expiry"`
+}
+
+// Valid reports whether the token is set and not about to expire. A zero
+// Expiry never expires.
+func (t *Token) Valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+
+	return t.Expiry.IsZero() || time.Now().Add(expiryDelta).Before(t.Expiry)
+}
+
+// TokenSource hands out tokens, modelled on golang.org/x/oauth2.TokenSource.
+// Implementations are called under a lock and need not be goroutine-safe.
+type TokenSource interface {
+	Token() (*Token, error)
+}
+
+// ContextTokenSource is a TokenSource that can be cancelled. The client
+// passes the context of the request that needs the token.
+type ContextTokenSource interface {
+	TokenSource
+	TokenContext(ctx context.Context) (*Token, error)
+}
+
+// WithTokenSource authenticates every request with tokens from source,
+// instead of the static access token given to NewApi. Tokens are cached
+// until they expire, and refreshed once if the server answers 401.
+func WithTokenSource(source TokenSource) Option {
+	return func(l *Lingotek) {
+		l.tokens = newReuseTokenSource(source)
+	}
+}
+
+// StaticTokenSource always returns the same access token.
+func StaticTokenSource(accessToken string) TokenSource {
+	return staticTokenSource{&Token{AccessToken: accessToken, TokenType: "bearer"}}
+}
+
+type staticTokenSource struct {
+	token *Token
+}
+
+func (s staticTokenSource) Token() (*Token, error) {
+	return s.token, nil
+}
+
+// ClientCredentialsTokenSource fetches tokens from tokenURL using the
+// OAuth2 client credentials grant. A nil client means http.DefaultClient.
+func ClientCredentialsTokenSource(client *http.Client, tokenURL, clientId, clientSecret string) TokenSource {
+	return &endpointTokenSource{
+		client:       client,
+		tokenURL:     tokenURL,
+		clientId:     clientId,
+		clientSecret: clientSecret,
+	}
+}
+
+// RefreshTokenSource exchanges refreshToken at tokenURL for new access
+// tokens. If the server rotates the refresh token, the new one is used
+// from then on. A nil client means http.DefaultClient.
+func RefreshTokenSource(client *http.Client, tokenURL, clientId, clientSecret, refreshToken string) TokenSource {
+	return &endpointTokenSource{
+		client:       client,
+		tokenURL:     tokenURL,
+		clientId:     clientId,
+		clientSecret: clientSecret,
+		refreshToken: refreshToken,
+	}
+}
+
+type endpointTokenSource struct {
+	client       *http.Client
+	tokenURL     string
+	clientId     string
+	clientSecret string
+	refreshToken string
+}
+
+func (e *endpointTokenSource) Token() (*Token, error) {
+	return e.TokenContext(context.Background())
+}
+
+func (e *endpointTokenSource) TokenContext(ctx context.Context) (*Token, error) {
+	ctx, cancel := context.WithTimeout(ctx, tokenTimeout)
+	defer cancel()
+
+	v := url.Values{}
+	v.Set("client_id", e.clientId)
+	v.Set("client_secret", e.clientSecret)
+	if e.refreshToken != "" {
+		v.Set("grant_type", "refresh_token")
+		v.Set("refresh_token", e.refreshToken)
+	} else {
+		v.Set("grant_type", "client_credentials")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", e.tokenURL, strings.NewReader(v.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded;charset=utf-8")
+
+	client := e.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, newAPIError("POST", e.tokenURL, resp, body)
+	}
+
+	var tokenResponse struct {
+		AccessToken  string `json:"access_token"`
+		TokenType    string `json:"token_type"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int64  `json:"expires_in"`
+	}
+
+	err = json.Unmarshal(body, &tokenResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	if tokenResponse.AccessToken == "" {
+		return nil, ErrNoToken
+	}
+
+	token := Token{
+		AccessToken:  tokenResponse.AccessToken,
+		TokenType:    tokenResponse.TokenType,
+		RefreshToken: tokenResponse.RefreshToken,
+	}
+	if tokenResponse.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(tokenResponse.ExpiresIn) * time.Second)
+	}
+
+	if token.RefreshToken != "" && e.refreshToken != "" {
+		e.refreshToken = token.RefreshToken
+	}
+
+	return &token, nil
+}
+
+// reuseTokenSource caches the token of the wrapped source until it
+// expires or is rejected by the server. Only one refresh runs at a time;
+// the lock is a channel so that callers waiting for it can give up when
+// their context is done.
+type reuseTokenSource struct {
+	lock   chan struct{}
+	source TokenSource
+	token  *Token
+}
+
+func newReuseTokenSource(source TokenSource) *reuseTokenSource {
+	return &reuseTokenSource{lock: make(chan struct{}, 1), source: source}
+}
+
+func (r *reuseTokenSource) Token() (*Token, error) {
+	return r.TokenContext(context.Background())
+}
+
+func (r *reuseTokenSource) TokenContext(ctx context.Context) (*Token, error) {
+	select {
+	case r.lock <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-r.lock }()
+
+	if r.token.Valid() {
+		return r.token, nil
+	}
+
+	var token *Token
+	var err error
+	if source, ok := r.source.(ContextTokenSource); ok {
+		token, err = source.TokenContext(ctx)
+	} else {
+		token, err = r.source.Token()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r.token = token
+	return token, nil
+}
+
+// invalidate drops stale from the cache. When concurrent requests are all
+// rejected with the same token, only the first one forces a refresh.
+func (r *reuseTokenSource) invalidate(stale *Token) {
+	r.lock <- struct{}{}
+	defer func() { <-r.lock }()
+
+	if r.token == stale {
+		r.token = nil
+	}
+}
+
+// authorization returns the Authorization header value for a request,
+// along with the token it was built from, if any.
+func (l *Lingotek) authorization(ctx context.Context) (string, *Token, error) {
+	if l.tokens == nil {
+		return l.AccessToken, nil, nil
+	}
+
+	token, err := l.tokens.TokenContext(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	tokenType := token.TokenType
+	if tokenType == "" {
+		tokenType = "bearer"
+	}
+
+	return tokenType + " " + token.AccessToken, token, nil
+}
@@ -0,0 +1,246 @@
+// Package bundle reads and writes nested i18n bundles, the JSON files of
+// i18next and vue-i18n and the YAML files of Rails, and flattens them into
+// segments for Lingotek.
+//
+// A bundle is uploaded as a flat JSON object mapping key paths such as
+// "cart.items.one" to their text; a dot within a key is escaped as "\.".
+// Translating puts the downloaded text back into a copy of the source
+// bundle, so key order, values that aren't text and the layout of the file
+// are kept. Text is never rewritten:
+// placeholders like {{name}} or %{count} and ICU blocks like
+// "{count, plural, one {# item} other {# items}}" are written back as
+// they were downloaded, quoted as the format requires.
+package bundle
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	lingotek "github.com/CuriousLLC/Lingotek"
+)
+
+// Lingotek format names of uploads and of the files bundles are read from.
+const (
+	FormatJSON = "JSON"
+	FormatYAML = "YAML"
+)
+
+type Kind int
+
+const (
+	Mapping Kind = iota
+	Sequence
+	Scalar
+)
+
+// Style is the way a YAML scalar was written. Translated text keeps the
+// style of its source where it can.
+type Style int
+
+const (
+	Plain Style = iota
+	SingleQuoted
+	DoubleQuoted
+	Literal
+	Folded
+)
+
+// Node is a value of a bundle.
+type Node struct {
+	Kind Kind
+	// Keys are the keys of a Mapping, in order, and Children the values
+	// of a Mapping or the items of a Sequence.
+	Keys     []string
+	Children []*Node
+	// Value is the text of a Scalar.
+	Value string
+	// Raw is set for scalars that aren't text, such as numbers, booleans
+	// and null, or YAML flow collections. Value then holds them as they
+	// were written, and they aren't translated.
+	Raw   bool
+	Style Style
+}
+
+// Bundle is a parsed JSON or YAML bundle.
+type Bundle struct {
+	Format string
+	// LocaleRoot is the locale key everything was nested under, as in
+	// Rails locale files, or "". It is left out of segment keys, and
+	// written around Root.
+	LocaleRoot string
+	Root       *Node
+}
+
+// Segment is a translatable text of a bundle.
+type Segment struct {
+	Key   string
+	Value string
+}
+
+// unwrap moves the content of a root mapping holding nothing but the
+// given locale, or its language, into the bundle.
+func (b *Bundle) unwrap(localeCode string) {
+	root := b.Root
+	if localeCode == "" || root.Kind != Mapping || len(root.Keys) != 1 || root.Children[0].Kind != Mapping {
+		return
+	}
+
+	key, tag := lingotek.CanonicalTag(root.Keys[0]), lingotek.CanonicalTag(localeCode)
+	if key == tag || key == language(tag) {
+		b.LocaleRoot = root.Keys[0]
+		b.Root = root.Children[0]
+	}
+}
+
+func language(tag string) string {
+	language, _, _ := strings.Cut(tag, "-")
+	return language
+}
+
+// root returns the node to write, wrapped in LocaleRoot if set.
+func (b *Bundle) root() *Node {
+	if b.LocaleRoot == "" {
+		return b.Root
+	}
+
+	return &Node{Kind: Mapping, Keys: []string{b.LocaleRoot}, Children: []*Node{b.Root}}
+}
+
+// keyEscaper escapes the dots of a key, and the escape itself, so that
+// {"a.b": ...} and {"a": {"b": ...}} get different key paths.
+var keyEscaper = strings.NewReplacer(`\`, `\\`, ".", `\.`)
+
+// walk calls fn for every text scalar with its key path. Keys are escaped
+// and joined with dots, and sequence items are keyed by their index.
+func walk(node *Node, prefix string, fn func(key string, node *Node)) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+
+	switch node.Kind {
+	case Mapping:
+		for i, child := range node.Children {
+			walk(child, join(keyEscaper.Replace(node.Keys[i])), fn)
+		}
+	case Sequence:
+		for i, child := range node.Children {
+			walk(child, join(strconv.Itoa(i)), fn)
+		}
+	default:
+		if !node.Raw {
+			fn(prefix, node)
+		}
+	}
+}
+
+// Segments returns the text of the bundle in order.
+func (b *Bundle) Segments() []Segment {
+	var segments []Segment
+	walk(b.Root, "", func(key string, node *Node) {
+		segments = append(segments, Segment{key, node.Value})
+	})
+
+	return segments
+}
+
+// Strings returns the text of the bundle keyed by path.
+func (b *Bundle) Strings() map[string]string {
+	values := make(map[string]string)
+	walk(b.Root, "", func(key string, node *Node) {
+		values[key] = node.Value
+	})
+
+	return values
+}
+
+// Translate returns a copy of the bundle with its text replaced by the
+// translations, keyed like Segments. Text without a translation keeps the
+// source text, and its key is returned in missing. A LocaleRoot is
+// replaced by localeCode, or just its language if the source's locale
+// root is a bare language like "en".
+func (b *Bundle) Translate(translations map[string]string, localeCode string) (translated *Bundle, missing []string) {
+	translated = &Bundle{Format: b.Format, Root: copyNode(b.Root)}
+	if b.LocaleRoot != "" {
+		translated.LocaleRoot = lingotek.CanonicalTag(localeCode)
+		if !strings.Contains(lingotek.CanonicalTag(b.LocaleRoot), "-") {
+			translated.LocaleRoot = language(translated.LocaleRoot)
+		}
+	}
+
+	walk(translated.Root, "", func(key string, node *Node) {
+		if value, ok := translations[key]; ok && value != "" {
+			node.Value = value
+		} else {
+			missing = append(missing, key)
+		}
+	})
+
+	return translated, missing
+}
+
+func copyNode(node *Node) *Node {
+	c := *node
+	c.Keys = append([]string(nil), node.Keys...)
+	c.Children = make([]*Node, len(node.Children))
+	for i, child := range node.Children {
+		c.Children[i] = copyNode(child)
+	}
+
+	return &c
+}
+
+// Write writes the bundle in its Format.
+func (b *Bundle) Write(w io.Writer) error {
+	var buf bytes.Buffer
+
+	if b.Format == FormatYAML {
+		writeYAML(&buf, b.root())
+	} else {
+		writeJSON(&buf, b.root(), "")
+		buf.WriteString("\n")
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// UploadRequest returns a request uploading the segments of the bundle to
+// a project as a flat JSON document in the given source locale.
+func (b *Bundle) UploadRequest(title, localeCode, projectId string) (lingotek.UploadRequest, error) {
+	flat := Node{Kind: Mapping}
+	for _, segment := range b.Segments() {
+		flat.Keys = append(flat.Keys, segment.Key)
+		flat.Children = append(flat.Children, &Node{Kind: Scalar, Value: segment.Value})
+	}
+
+	var buf bytes.Buffer
+	writeJSON(&buf, &flat, "")
+	buf.WriteString("\n")
+
+	return lingotek.UploadRequest{
+		Title:      title,
+		LocaleCode: localeCode,
+		ProjectId:  projectId,
+		Format:     FormatJSON,
+		Charset:    "UTF-8",
+		Reader:     &buf,
+	}, nil
+}
+
+// ParseTranslations reads a translation downloaded for a bundle uploaded
+// with UploadRequest.
+func ParseTranslations(r io.Reader) (map[string]string, error) {
+	var translations map[string]string
+	err := json.NewDecoder(r).Decode(&translations)
+	if err != nil {
+		return nil, err
+	}
+
+	return translations, nil
+}
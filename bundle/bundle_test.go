@@ -0,0 +1,237 @@
+package bundle
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func parseFile(t *testing.T, path string, parse func(io.Reader, string) (*Bundle, error)) (*Bundle, []byte) {
+	original, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := parse(bytes.NewReader(original), "en-US")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b, original
+}
+
+func write(t *testing.T, b *Bundle) string {
+	var buf bytes.Buffer
+	if err := b.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.String()
+}
+
+func TestJSON(t *testing.T) {
+	b, original := parseFile(t, "../test_data/i18n_en.json", ParseJSON)
+
+	expected := []Segment{
+		{"welcome", "Welcome, {{name}}!"},
+		{"cart.title", "Your cart"},
+		{"cart.items_one", "{{count}} item"},
+		{"cart.items_other", "{{count}} items"},
+		{"cart.summary", "{count, plural, one {# item} other {# items}} for <b>{total}</b>"},
+		{"steps.0", "Pick"},
+		{"steps.1", "Pay & ship"},
+	}
+	if segments := b.Segments(); !reflect.DeepEqual(segments, expected) {
+		t.Errorf("Expected segments\n%q\ngot\n%q", expected, segments)
+	}
+
+	if output := write(t, b); output != string(original) {
+		t.Errorf("Expected\n%s\ngot\n%s", original, output)
+	}
+}
+
+func TestDottedKeys(t *testing.T) {
+	b, err := ParseJSON(strings.NewReader(`{"a.b": "flat", "a": {"b": "nested"}, "c\\d": "slash"}`), "en-US")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Segment{
+		{`a\.b`, "flat"},
+		{"a.b", "nested"},
+		{`c\\d`, "slash"},
+	}
+	if segments := b.Segments(); !reflect.DeepEqual(segments, expected) {
+		t.Errorf("Expected segments\n%q\ngot\n%q", expected, segments)
+	}
+
+	translated, missing := b.Translate(map[string]string{`a\.b`: "plat", "a.b": "imbriqué", `c\\d`: "barre"}, "fr-FR")
+	if len(missing) != 0 {
+		t.Errorf("Expected every key to be translated, got %v missing", missing)
+	}
+
+	expectedOutput := `{
+  "a.b": "plat",
+  "a": {
+    "b": "imbriqué"
+  },
+  "c\\d": "barre"
+}
+`
+	if output := write(t, translated); output != expectedOutput {
+		t.Errorf("Expected\n%s\ngot\n%s", expectedOutput, output)
+	}
+}
+
+func TestYAML(t *testing.T) {
+	b, original := parseFile(t, "../test_data/i18n_en.yml", ParseYAML)
+
+	if b.LocaleRoot != "en" {
+		t.Errorf("Expected the locale root to be unwrapped, got %q", b.LocaleRoot)
+	}
+
+	expected := []Segment{
+		{"greeting", "Hello"},
+		{"inbox.unread", "%{count} unread messages"},
+		{"inbox.empty", "Nothing here, it's quiet"},
+		{"inbox.help", "First line\nSecond line\n"},
+		{"date.day_names.0", "Sunday"},
+		{"date.day_names.1", "Monday"},
+	}
+	if segments := b.Segments(); !reflect.DeepEqual(segments, expected) {
+		t.Errorf("Expected segments\n%q\ngot\n%q", expected, segments)
+	}
+
+	if output := write(t, b); output != string(original) {
+		t.Errorf("Expected\n%s\ngot\n%s", original, output)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	b, _ := parseFile(t, "../test_data/i18n_en.yml", ParseYAML)
+
+	upload, err := b.UploadRequest("en.yml", "en-US", "project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upload.Format != FormatJSON {
+		t.Errorf("Unexpected format %s", upload.Format)
+	}
+
+	// Pretend Lingotek translated the uploaded segments
+	uploaded, err := ParseTranslations(upload.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uploaded) != 6 || uploaded["inbox.unread"] != "%{count} unread messages" {
+		t.Errorf("Unexpected upload %q", uploaded)
+	}
+
+	translations := map[string]string{
+		"greeting":         "Hola: amigo",
+		"inbox.unread":     "%{count} mensajes sin leer",
+		"inbox.empty":      "Nada aquí",
+		"inbox.help":       "Primera línea\nSegunda línea\n",
+		"date.day_names.0": "domingo",
+		"date.day_names.1": "",
+	}
+
+	translated, missing := b.Translate(translations, "es-ES")
+	if expected := []string{"date.day_names.1"}; !reflect.DeepEqual(missing, expected) {
+		t.Errorf("Expected %v to be missing, got %v", expected, missing)
+	}
+
+	expected := `es:
+  greeting: "Hola: amigo"
+  inbox:
+    unread: "%{count} mensajes sin leer"
+    empty: 'Nada aquí'
+    help: |
+      Primera línea
+      Segunda línea
+  date:
+    order: [:year, :month, :day]
+    day_names:
+      - domingo
+      - Monday
+  enabled: true
+`
+	if output := write(t, translated); output != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, output)
+	}
+
+	// The source is left alone
+	if b.Strings()["greeting"] != "Hello" || b.LocaleRoot != "en" {
+		t.Errorf("Expected the source bundle not to change")
+	}
+}
+
+func TestYAMLScalars(t *testing.T) {
+	input := `# Comments are dropped
+---
+"no": "Norsk"
+plain: value # trailing comment
+escaped: "Tab\there \u00e9"
+folded: >-
+  one
+  two
+
+  three
+keep: |+
+  text
+
+nothing:
+yes_word: "yes"
+`
+	b, err := ParseYAML(strings.NewReader(input), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"no":       "Norsk",
+		"plain":    "value",
+		"escaped":  "Tab\there é",
+		"folded":   "one two\nthree",
+		"keep":     "text\n\n",
+		"yes_word": "yes",
+	}
+	if values := b.Strings(); !reflect.DeepEqual(values, expected) {
+		t.Errorf("Expected\n%q\ngot\n%q", expected, values)
+	}
+
+	// Values that would read back differently are quoted
+	output := write(t, b)
+	for _, line := range []string{`"no": "Norsk"`, `yes_word: "yes"`, "nothing:\n", `keep: "text\n\n"`} {
+		if !strings.Contains(output, line) {
+			t.Errorf("Expected %q in\n%s", line, output)
+		}
+	}
+
+	reparsed, err := ParseYAML(strings.NewReader(output), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values := reparsed.Strings(); !reflect.DeepEqual(values, expected) {
+		t.Errorf("Expected\n%q\ngot\n%q", expected, values)
+	}
+}
+
+func TestYAMLErrors(t *testing.T) {
+	tests := []string{
+		"a: &anchor b\n",
+		"a: 'unterminated\n",
+		"a:\n  b: c\n d: e\n",
+		"- a: b\n",
+		"just text\n",
+	}
+
+	for _, test := range tests {
+		if _, err := ParseYAML(strings.NewReader(test), ""); err == nil {
+			t.Errorf("Expected %q to fail", test)
+		}
+	}
+}
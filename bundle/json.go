@@ -0,0 +1,117 @@
+package bundle
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ParseJSON reads a JSON bundle. If everything is nested under localeCode,
+// as in a vue-i18n file holding a single locale, it becomes the
+// LocaleRoot.
+func ParseJSON(r io.Reader, localeCode string) (*Bundle, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	root, err := parseJSON(decoder)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("bundle: data after the top-level value")
+	}
+
+	b := Bundle{Format: FormatJSON, Root: root}
+	b.unwrap(localeCode)
+	return &b, nil
+}
+
+func parseJSON(decoder *json.Decoder) (*Node, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token := token.(type) {
+	case json.Delim:
+		node := Node{Kind: Mapping}
+		if token == '[' {
+			node.Kind = Sequence
+		}
+
+		for decoder.More() {
+			if node.Kind == Mapping {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.Keys = append(node.Keys, key.(string))
+			}
+
+			child, err := parseJSON(decoder)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, child)
+		}
+
+		// The closing delimiter
+		_, err = decoder.Token()
+		return &node, err
+	case string:
+		return &Node{Kind: Scalar, Value: token}, nil
+	case json.Number:
+		return &Node{Kind: Scalar, Value: token.String(), Raw: true}, nil
+	case bool:
+		return &Node{Kind: Scalar, Value: fmt.Sprint(token), Raw: true}, nil
+	default:
+		return &Node{Kind: Scalar, Value: "null", Raw: true}, nil
+	}
+}
+
+// writeJSON writes node indented by two spaces per level. Unlike
+// json.Marshal it leaves <, > and & alone, which are common in text.
+func writeJSON(buf *bytes.Buffer, node *Node, indent string) {
+	switch node.Kind {
+	case Mapping, Sequence:
+		open, close := "{", "}"
+		if node.Kind == Sequence {
+			open, close = "[", "]"
+		}
+
+		buf.WriteString(open)
+		for i, child := range node.Children {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n" + indent + "  ")
+			if node.Kind == Mapping {
+				writeJSONString(buf, node.Keys[i])
+				buf.WriteString(": ")
+			}
+			writeJSON(buf, child, indent+"  ")
+		}
+		if len(node.Children) > 0 {
+			buf.WriteString("\n" + indent)
+		}
+		buf.WriteString(close)
+	default:
+		if node.Raw {
+			buf.WriteString(node.Value)
+		} else {
+			writeJSONString(buf, node.Value)
+		}
+	}
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+
+	// Encode ends every value with a newline
+	buf.Truncate(buf.Len() - 1)
+}
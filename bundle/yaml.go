@@ -0,0 +1,498 @@
+package bundle
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseYAML reads a YAML bundle. Only the part of YAML that i18n files
+// use is supported: block mappings and sequences of scalars, plain,
+// quoted and block scalars, and comments, which are dropped. Flow
+// collections such as [a, b] are kept as they were written but not
+// translated. Anchors, aliases and tags are rejected.
+//
+// If everything is nested under localeCode, as in Rails locale files, it
+// becomes the LocaleRoot.
+func ParseYAML(r io.Reader, localeCode string) (*Bundle, error) {
+	var p yamlParser
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		p.lines = append(p.lines, strings.TrimRight(scanner.Text(), " \t\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	root := &Node{Kind: Mapping}
+	indent, ok := p.next()
+	if ok {
+		var err error
+		root, err = p.parseNode(indent)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, ok := p.next(); ok {
+		return nil, p.errorf("unexpected indentation")
+	}
+
+	b := Bundle{Format: FormatYAML, Root: root}
+	b.unwrap(localeCode)
+	return &b, nil
+}
+
+type yamlParser struct {
+	lines []string
+	pos   int
+}
+
+func (p *yamlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("bundle: line %d: %s", p.pos+1, fmt.Sprintf(format, args...))
+}
+
+// next skips blank lines, comments and document markers, and returns the
+// indentation of the next line with content.
+func (p *yamlParser) next() (int, bool) {
+	for ; p.pos < len(p.lines); p.pos++ {
+		line := p.lines[p.pos]
+		content := strings.TrimLeft(line, " ")
+
+		if content == "" || content[0] == '#' || line == "---" || line == "..." || strings.HasPrefix(line, "%") {
+			continue
+		}
+
+		return len(line) - len(content), true
+	}
+
+	return 0, false
+}
+
+func (p *yamlParser) content() string {
+	return strings.TrimLeft(p.lines[p.pos], " ")
+}
+
+func isItem(content string) bool {
+	return content == "-" || strings.HasPrefix(content, "- ")
+}
+
+func (p *yamlParser) parseNode(indent int) (*Node, error) {
+	if strings.HasPrefix(p.content(), "\t") {
+		return nil, p.errorf("tabs can't be used for indentation")
+	}
+
+	if isItem(p.content()) {
+		return p.parseSequence(indent)
+	}
+
+	return p.parseMapping(indent)
+}
+
+func (p *yamlParser) parseMapping(indent int) (*Node, error) {
+	node := Node{Kind: Mapping}
+
+	for {
+		current, ok := p.next()
+		if !ok || current < indent {
+			return &node, nil
+		}
+		if current > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+
+		content := p.content()
+		if isItem(content) {
+			return nil, p.errorf("sequence item in a mapping")
+		}
+
+		key, rest, err := p.parseKey(content)
+		if err != nil {
+			return nil, err
+		}
+
+		child, err := p.parseValue(indent, rest, true)
+		if err != nil {
+			return nil, err
+		}
+
+		node.Keys = append(node.Keys, key)
+		node.Children = append(node.Children, child)
+	}
+}
+
+func (p *yamlParser) parseSequence(indent int) (*Node, error) {
+	node := Node{Kind: Sequence}
+
+	for {
+		current, ok := p.next()
+		if !ok || current < indent || current == indent && !isItem(p.content()) {
+			return &node, nil
+		}
+		if current > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+
+		rest := strings.TrimSpace(strings.TrimPrefix(p.content(), "-"))
+		if _, _, err := p.parseKey(rest); err == nil && !strings.HasPrefix(rest, `"`) && !strings.HasPrefix(rest, "'") {
+			return nil, p.errorf("mappings in sequences aren't supported")
+		}
+
+		child, err := p.parseValue(indent, rest, false)
+		if err != nil {
+			return nil, err
+		}
+
+		node.Children = append(node.Children, child)
+	}
+}
+
+// parseKey splits "key: value" into the key and the rest of the line.
+func (p *yamlParser) parseKey(content string) (string, string, error) {
+	var key, rest string
+
+	if content != "" && (content[0] == '"' || content[0] == '\'') {
+		var err error
+		key, rest, err = p.parseQuoted(content)
+		if err != nil {
+			return "", "", err
+		}
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", p.errorf("expected a colon after the key")
+		}
+		rest = rest[1:]
+	} else {
+		i := strings.Index(content+" ", ": ")
+		if i < 0 {
+			return "", "", p.errorf("expected a key")
+		}
+		key, rest = content[:i], content[i+1:]
+	}
+
+	if rest != "" && rest[0] != ' ' {
+		return "", "", p.errorf("expected a space after the colon")
+	}
+
+	return key, strings.TrimSpace(rest), nil
+}
+
+// parseValue parses the value following a key or sequence item on the
+// current line, and any lines belonging to it. The current line is
+// consumed.
+func (p *yamlParser) parseValue(indent int, rest string, inMapping bool) (*Node, error) {
+	p.pos++
+
+	if rest == "" || rest[0] == '#' {
+		current, ok := p.next()
+		if ok && (current > indent || inMapping && current == indent && isItem(p.content())) {
+			return p.parseNode(current)
+		}
+
+		return &Node{Kind: Scalar, Raw: true}, nil
+	}
+
+	switch rest[0] {
+	case '|', '>':
+		return p.parseBlock(indent, rest)
+	case '"', '\'':
+		p.pos--
+		value, after, err := p.parseQuoted(rest)
+		p.pos++
+		if err != nil {
+			return nil, err
+		}
+		if after = strings.TrimSpace(after); after != "" && after[0] != '#' {
+			return nil, p.errorf("unexpected text after a quoted string")
+		}
+
+		style := DoubleQuoted
+		if rest[0] == '\'' {
+			style = SingleQuoted
+		}
+		return &Node{Kind: Scalar, Value: value, Style: style}, nil
+	case '[', '{':
+		return &Node{Kind: Scalar, Value: rest, Raw: true}, nil
+	case '&', '*', '!':
+		p.pos--
+		return nil, p.errorf("anchors, aliases and tags aren't supported")
+	}
+
+	if i := strings.Index(rest, " #"); i >= 0 {
+		rest = strings.TrimSpace(rest[:i])
+	}
+
+	return &Node{Kind: Scalar, Value: rest, Raw: isRawScalar(rest)}, nil
+}
+
+// parseBlock parses a literal or folded block scalar whose header is the
+// rest of the previous line.
+func (p *yamlParser) parseBlock(indent int, header string) (*Node, error) {
+	style := Literal
+	if header[0] == '>' {
+		style = Folded
+	}
+
+	indicator := header[1:]
+	if i := strings.Index(indicator, "#"); i >= 0 {
+		indicator = indicator[:i]
+	}
+
+	chomp := strings.TrimSpace(indicator)
+	if chomp != "" && chomp != "-" && chomp != "+" {
+		p.pos--
+		return nil, p.errorf("unsupported block scalar header %q", header)
+	}
+
+	var lines []string
+	blockIndent := -1
+	for ; p.pos < len(p.lines); p.pos++ {
+		line := p.lines[p.pos]
+		content := strings.TrimLeft(line, " ")
+		current := len(line) - len(content)
+
+		if content == "" {
+			lines = append(lines, "")
+			continue
+		}
+		if current <= indent {
+			break
+		}
+		if blockIndent < 0 {
+			blockIndent = current
+		}
+		if current < blockIndent {
+			return nil, p.errorf("unexpected indentation")
+		}
+
+		lines = append(lines, line[blockIndent:])
+	}
+
+	// Trailing blank lines only matter for chomping
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+
+	var value string
+	if style == Literal {
+		value = strings.Join(lines, "\n")
+	} else {
+		value = fold(lines)
+	}
+
+	if len(lines) > 0 {
+		switch chomp {
+		case "":
+			value += "\n"
+		case "+":
+			value += strings.Repeat("\n", trailing+1)
+		}
+	}
+
+	return &Node{Kind: Scalar, Value: value, Style: style}, nil
+}
+
+// fold joins the lines of a folded scalar: lines are joined by spaces,
+// each blank line becomes a newline and more indented lines keep their
+// line breaks.
+func fold(lines []string) string {
+	var b strings.Builder
+
+	for i, line := range lines {
+		if i > 0 {
+			previous := lines[i-1]
+			switch {
+			case line == "":
+				b.WriteString("\n")
+			case previous == "":
+			case strings.HasPrefix(line, " ") || strings.HasPrefix(previous, " "):
+				b.WriteString("\n")
+			default:
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString(line)
+	}
+
+	return b.String()
+}
+
+// parseQuoted parses the quoted string content starts with, returning it
+// and the rest of the line.
+func (p *yamlParser) parseQuoted(content string) (string, string, error) {
+	quote := content[0]
+	var b strings.Builder
+
+	for i := 1; i < len(content); i++ {
+		c := content[i]
+
+		switch {
+		case quote == '\'' && c == '\'':
+			if i+1 < len(content) && content[i+1] == '\'' {
+				b.WriteByte('\'')
+				i++
+				continue
+			}
+			return b.String(), content[i+1:], nil
+		case quote == '"' && c == '"':
+			return b.String(), content[i+1:], nil
+		case quote == '"' && c == '\\':
+			if i+1 == len(content) {
+				return "", "", p.errorf("multi-line strings aren't supported")
+			}
+			n, err := unescapeYAML(&b, content[i+1:])
+			if err != nil {
+				return "", "", p.errorf("%v", err)
+			}
+			i += n
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", "", p.errorf("multi-line strings aren't supported")
+}
+
+// unescapeYAML writes the character escaped by the sequence s starts
+// with, and returns the length of the sequence.
+func unescapeYAML(b *strings.Builder, s string) (int, error) {
+	simple := map[byte]string{
+		'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n", 'v': "\v", 'f': "\f",
+		'r': "\r", 'e': "\x1b", ' ': " ", '"': `"`, '/': "/", '\\': `\`, 'N': "\u0085",
+		'_': " ", 'L': " ", 'P': " ",
+	}
+	if r, ok := simple[s[0]]; ok {
+		b.WriteString(r)
+		return 1, nil
+	}
+
+	digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[s[0]]
+	if digits == 0 || len(s) < digits+1 {
+		return 0, fmt.Errorf("invalid escape \\%c", s[0])
+	}
+
+	code, err := strconv.ParseUint(s[1:digits+1], 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid escape \\%s", s[:digits+1])
+	}
+
+	b.WriteRune(rune(code))
+	return digits + 1, nil
+}
+
+var rawScalar = regexp.MustCompile(`^(~|null|Null|NULL|true|True|TRUE|false|False|FALSE|yes|Yes|YES|no|No|NO|on|On|ON|off|Off|OFF|[-+]?(\d[\d_]*)?\.?\d[\d_]*([eE][-+]?\d+)?|0x[0-9a-fA-F_]+|0o?[0-7_]+|[-+]?\.(inf|Inf|INF)|\.(nan|NaN|NAN))$`)
+
+// isRawScalar reports whether a plain scalar is read as something other
+// than a string. The YAML 1.1 booleans such as "no" are included, as
+// Ruby reads them so.
+func isRawScalar(s string) bool {
+	return rawScalar.MatchString(s)
+}
+
+// isPlainSafe reports whether s can be written as a plain scalar and be
+// read back as the same string.
+func isPlainSafe(s string) bool {
+	if s == "" || isRawScalar(s) || strings.TrimSpace(s) != s {
+		return false
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return false
+	}
+
+	return !strings.ContainsAny(s, "\n\t\r") && !strings.Contains(s, ": ") && !strings.Contains(s, " #") && !strings.HasSuffix(s, ":")
+}
+
+func writeYAML(buf *bytes.Buffer, node *Node) {
+	if node.Kind == Scalar {
+		buf.WriteString(yamlScalar(node, "") + "\n")
+		return
+	}
+
+	writeYAMLNode(buf, node, "")
+}
+
+func writeYAMLNode(buf *bytes.Buffer, node *Node, indent string) {
+	for i, child := range node.Children {
+		if node.Kind == Mapping {
+			buf.WriteString(indent + yamlKey(node.Keys[i]) + ":")
+		} else {
+			buf.WriteString(indent + "-")
+		}
+
+		switch {
+		case child.Kind == Scalar:
+			if value := yamlScalar(child, indent+"  "); value != "" {
+				buf.WriteString(" " + value)
+			}
+			buf.WriteString("\n")
+		case len(child.Children) == 0 && child.Kind == Mapping:
+			buf.WriteString(" {}\n")
+		case len(child.Children) == 0:
+			buf.WriteString(" []\n")
+		default:
+			buf.WriteString("\n")
+			writeYAMLNode(buf, child, indent+"  ")
+		}
+	}
+}
+
+func yamlKey(key string) string {
+	if isPlainSafe(key) {
+		return key
+	}
+
+	return doubleQuote(key)
+}
+
+// yamlScalar formats a scalar in its style if the value allows, and
+// double quoted otherwise. Block scalars are indented by indent.
+func yamlScalar(node *Node, indent string) string {
+	if node.Raw {
+		return node.Value
+	}
+
+	value := node.Value
+	switch node.Style {
+	case Plain:
+		if isPlainSafe(value) {
+			return value
+		}
+	case SingleQuoted:
+		if !strings.ContainsAny(value, "\n\t\r") && utf8.ValidString(value) {
+			return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+		}
+	case Literal, Folded:
+		// Folded text is written literally, which reads back the same
+		if value != "" && !strings.HasSuffix(value, "\n\n") && !strings.HasPrefix(value, " ") && !strings.HasPrefix(value, "\n") {
+			header := "|-"
+			if strings.HasSuffix(value, "\n") {
+				header = "|"
+			}
+
+			var b strings.Builder
+			b.WriteString(header)
+			for _, line := range strings.Split(strings.TrimSuffix(value, "\n"), "\n") {
+				b.WriteString("\n")
+				if line != "" {
+					b.WriteString(indent + line)
+				}
+			}
+			return b.String()
+		}
+	}
+
+	return doubleQuote(value)
+}
+
+var yamlEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`, "\x00", `\0`)
+
+func doubleQuote(s string) string {
+	return `"` + yamlEscaper.Replace(s) + `"`
+}
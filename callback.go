@@ -0,0 +1,260 @@
+package lingotek
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxCallbackBody caps the size of a callback request body. Callbacks are a
+// handful of parameters, so anything larger isn't from Lingotek.
+const maxCallbackBody = 64 << 10
+
+// DocumentUploaded is sent once Lingotek has imported a new document.
+type DocumentUploaded struct {
+	DocumentId string
+	ProjectId  string
+	Params     url.Values
+}
+
+// TranslationCompleted is sent when a translation has passed every phase.
+type TranslationCompleted struct {
+	DocumentId string
+	ProjectId  string
+	LocaleCode string
+	Params     url.Values
+}
+
+// PhaseCompleted is sent when one phase of a translation's workflow is done.
+type PhaseCompleted struct {
+	DocumentId string
+	ProjectId  string
+	LocaleCode string
+	PhaseName  string
+	Progress   int
+	Params     url.Values
+}
+
+// CallbackHandler receives the requests Lingotek sends to a project's or
+// document's callback URL, and dispatches them to the registered funcs.
+// Register funcs before serving; CallbackHandler is safe for concurrent
+// requests once it is serving.
+//
+// A func returning an error makes the handler answer 500, so Lingotek
+// delivers the callback again later.
+type CallbackHandler struct {
+	secret string
+
+	documentUploaded     []func(context.Context, DocumentUploaded) error
+	translationCompleted []func(context.Context, TranslationCompleted) error
+	phaseCompleted       []func(context.Context, PhaseCompleted) error
+
+	mu     sync.Mutex
+	window time.Duration
+	seen   map[string]time.Time
+	pruned time.Time
+}
+
+// NewCallbackHandler returns a handler that only accepts requests whose
+// "secret" parameter equals secret. Add the secret to the callback URL you
+// give Lingotek. An empty secret accepts every request.
+func NewCallbackHandler(secret string) *CallbackHandler {
+	return &CallbackHandler{secret: secret}
+}
+
+func (h *CallbackHandler) OnDocumentUploaded(f func(context.Context, DocumentUploaded) error) {
+	h.documentUploaded = append(h.documentUploaded, f)
+}
+
+func (h *CallbackHandler) OnTranslationCompleted(f func(context.Context, TranslationCompleted) error) {
+	h.translationCompleted = append(h.translationCompleted, f)
+}
+
+func (h *CallbackHandler) OnPhaseCompleted(f func(context.Context, PhaseCompleted) error) {
+	h.phaseCompleted = append(h.phaseCompleted, f)
+}
+
+// Deduplicate drops callbacks identical to one being handled, or handled
+// successfully within window. Lingotek may deliver the same callback more
+// than once.
+func (h *CallbackHandler) Deduplicate(window time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.window = window
+	h.seen = make(map[string]time.Time)
+}
+
+func (h *CallbackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxCallbackBody)
+
+	params, err := callbackParams(r)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if h.secret != "" && subtle.ConstantTimeCompare([]byte(params.Get("secret")), []byte(h.secret)) != 1 {
+		http.Error(w, "invalid secret", http.StatusUnauthorized)
+		return
+	}
+	params.Del("secret")
+
+	if params.Get("document_id") == "" {
+		http.Error(w, "missing document_id", http.StatusBadRequest)
+		return
+	}
+
+	key := params.Encode()
+	if !h.reserve(key) {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	err = h.dispatch(r.Context(), params)
+	if err != nil {
+		h.release(key)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.markSeen(key)
+	w.WriteHeader(http.StatusOK)
+}
+
+// callbackParams merges the query string with a form or JSON body.
+func callbackParams(r *http.Request) (url.Values, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		err := r.ParseForm()
+		return r.Form, err
+	}
+
+	params := r.URL.Query()
+
+	// Numbers are kept as sent, so a large ID isn't turned into 1e+06
+	var body map[string]interface{}
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	err := decoder.Decode(&body)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range body {
+		if value != nil {
+			params.Set(key, fmt.Sprint(value))
+		}
+	}
+
+	return params, nil
+}
+
+func (h *CallbackHandler) dispatch(ctx context.Context, params url.Values) error {
+	documentId := params.Get("document_id")
+	projectId := params.Get("project_id")
+	localeCode := params.Get("locale_code")
+	progress, _ := strconv.Atoi(params.Get("progress"))
+	complete := params.Get("complete") == "true" || progress >= 100
+
+	switch params.Get("type") {
+	case "document_uploaded":
+		event := DocumentUploaded{documentId, projectId, params}
+		for _, f := range h.documentUploaded {
+			if err := f(ctx, event); err != nil {
+				return err
+			}
+		}
+	case "target":
+		// Targets also report partial progress, which we don't surface
+		if !complete {
+			return nil
+		}
+
+		event := TranslationCompleted{documentId, projectId, localeCode, params}
+		for _, f := range h.translationCompleted {
+			if err := f(ctx, event); err != nil {
+				return err
+			}
+		}
+	case "phase":
+		phaseName := params.Get("phase_name")
+		if phaseName == "" {
+			phaseName = params.Get("phase")
+		}
+
+		event := PhaseCompleted{documentId, projectId, localeCode, phaseName, progress, params}
+		for _, f := range h.phaseCompleted {
+			if err := f(ctx, event); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// reserve claims key for the caller, returning false when the same callback
+// is already being handled or was handled within the window. Checking and
+// claiming happen under one lock, so concurrent duplicates run only once.
+func (h *CallbackHandler) reserve(key string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.seen == nil {
+		return true
+	}
+
+	now := time.Now()
+	if now.Sub(h.pruned) >= h.window {
+		for seenKey, when := range h.seen {
+			if now.Sub(when) >= h.window {
+				delete(h.seen, seenKey)
+			}
+		}
+		h.pruned = now
+	}
+
+	when, ok := h.seen[key]
+	if ok && now.Sub(when) < h.window {
+		return false
+	}
+
+	h.seen[key] = now
+	return true
+}
+
+// release gives up a reservation after a failed delivery, so Lingotek's
+// retry is handled again.
+func (h *CallbackHandler) release(key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.seen != nil {
+		delete(h.seen, key)
+	}
+}
+
+// markSeen restarts the window once a delivery has been handled.
+func (h *CallbackHandler) markSeen(key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.seen != nil {
+		h.seen[key] = time.Now()
+	}
+}
@@ -0,0 +1,401 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	lingotek "github.com/CuriousLLC/Lingotek"
+	"github.com/CuriousLLC/Lingotek/validate"
+)
+
+type cli struct {
+	api    *lingotek.Lingotek
+	out    *printer
+	stderr io.Writer
+}
+
+var commands = map[string]func(*cli, context.Context, []string) error{
+	"communities list":      (*cli).listCommunities,
+	"projects list":         (*cli).listProjects,
+	"documents list":        (*cli).listDocuments,
+	"documents get":         (*cli).getDocument,
+	"documents upload":      (*cli).uploadDocument,
+	"documents status":      (*cli).documentStatus,
+	"documents delete":      (*cli).deleteDocument,
+	"translations add":      (*cli).addTranslation,
+	"translations list":     (*cli).listTranslations,
+	"translations download": (*cli).downloadTranslation,
+	"locales list":          (*cli).listLocales,
+}
+
+// parse parses the flags of a command and checks it was given exactly
+// nargs arguments.
+func (c *cli) parse(flags *flag.FlagSet, args []string, nargs int) error {
+	flags.SetOutput(c.stderr)
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+
+	if flags.NArg() != nargs {
+		return errUsage
+	}
+
+	return nil
+}
+
+func (c *cli) listCommunities(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("communities list", flag.ContinueOnError)
+	if err := c.parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	communities, err := c.api.CommunitiesPager(nil).Collect(ctx)
+	if err != nil {
+		return err
+	}
+
+	var rows [][]string
+	for _, community := range communities {
+		rows = append(rows, []string{community.Property.Id, community.Property.Title})
+	}
+
+	return c.out.table(communities, []string{"ID", "TITLE"}, rows)
+}
+
+func (c *cli) listProjects(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("projects list", flag.ContinueOnError)
+	communityId := flags.String("community", "", "community ID")
+	if err := c.parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	if *communityId == "" {
+		return errUsage
+	}
+
+	community := lingotek.Community{}
+	community.Property.Id = *communityId
+
+	projects, err := c.api.ProjectsPager(&community, nil).Collect(ctx)
+	if err != nil {
+		return err
+	}
+
+	var rows [][]string
+	for _, project := range projects {
+		rows = append(rows, []string{
+			project.Property.Id,
+			project.Property.Title,
+			project.Property.WorkflowId,
+			formatDate(project.Property.DueDate),
+		})
+	}
+
+	return c.out.table(projects, []string{"ID", "TITLE", "WORKFLOW", "DUE"}, rows)
+}
+
+func (c *cli) listDocuments(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("documents list", flag.ContinueOnError)
+	projectId := flags.String("project", "", "only list documents of this project")
+	title := flags.String("title", "", "only list documents whose title contains this")
+	limit := flags.Int("limit", 0, "list at most this many documents")
+	if err := c.parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	opts := lingotek.DocumentListOptions{
+		ProjectId: *projectId,
+		Title:     *title,
+	}
+	opts.PageSize = 100
+	opts.MaxItems = *limit
+
+	documents, err := c.api.DocumentsPager(&opts).Collect(ctx)
+	if err != nil {
+		return err
+	}
+
+	var rows [][]string
+	for _, document := range documents {
+		rows = append(rows, documentRow(&document))
+	}
+
+	return c.out.table(documents, documentHeader, rows)
+}
+
+var documentHeader = []string{"ID", "TITLE", "LOCALE", "PROGRESS", "UPLOADED"}
+
+func documentRow(document *lingotek.Document) []string {
+	return []string{
+		document.Property.Id,
+		document.Property.Title,
+		document.Locale.Property.Code,
+		strconv.Itoa(int(document.Status.Property.Progress)) + "%",
+		formatDate(document.Property.UploadDate),
+	}
+}
+
+func (c *cli) getDocument(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("documents get", flag.ContinueOnError)
+	if err := c.parse(flags, args, 1); err != nil {
+		return err
+	}
+
+	document, err := c.api.GetDocumentContext(ctx, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	return c.out.table(document, documentHeader, [][]string{documentRow(document)})
+}
+
+func (c *cli) uploadDocument(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("documents upload", flag.ContinueOnError)
+	projectId := flags.String("project", "", "project ID")
+	localeCode := flags.String("locale", "", "source locale code, e.g. en-US")
+	title := flags.String("title", "", "document title, defaults to the file name")
+	format := flags.String("format", "", "content format, guessed by Lingotek if empty")
+	if err := c.parse(flags, args, 1); err != nil {
+		return err
+	}
+
+	if *projectId == "" || *localeCode == "" {
+		return errUsage
+	}
+
+	path := flags.Arg(0)
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if *title == "" {
+		*title = filepath.Base(path)
+	}
+
+	status, err := c.api.UploadDocumentContext(ctx, lingotek.UploadRequest{
+		Title:      *title,
+		LocaleCode: *localeCode,
+		ProjectId:  *projectId,
+		Format:     *format,
+		Filename:   filepath.Base(path),
+		Reader:     file,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.out.message(status, "%s", status.Property.Id)
+}
+
+func (c *cli) documentStatus(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("documents status", flag.ContinueOnError)
+	if err := c.parse(flags, args, 1); err != nil {
+		return err
+	}
+
+	document := lingotek.Document{}
+	document.Property.Id = flags.Arg(0)
+
+	current, err := c.api.CheckStatusContext(ctx, document)
+	if err != nil {
+		return err
+	}
+
+	status := current.Status.Property
+	row := []string{
+		current.Property.Id,
+		strconv.Itoa(int(status.Progress)) + "%",
+		strconv.Itoa(status.Count.Word.Total),
+		strconv.Itoa(status.Count.Segment.Total),
+	}
+
+	return c.out.table(current.Status, []string{"ID", "PROGRESS", "WORDS", "SEGMENTS"}, [][]string{row})
+}
+
+func (c *cli) deleteDocument(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("documents delete", flag.ContinueOnError)
+	if err := c.parse(flags, args, 1); err != nil {
+		return err
+	}
+
+	document := lingotek.Document{}
+	document.Property.Id = flags.Arg(0)
+
+	err := c.api.DeleteDocumentContext(ctx, &document)
+	if err != nil {
+		return err
+	}
+
+	return c.out.message(map[string]string{"deleted": document.Property.Id}, "deleted %s", document.Property.Id)
+}
+
+func (c *cli) addTranslation(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("translations add", flag.ContinueOnError)
+	workflowId := flags.String("workflow", "", "workflow ID, defaults to the project's")
+	if err := c.parse(flags, args, 2); err != nil {
+		return err
+	}
+
+	document := lingotek.Document{}
+	document.Property.Id = flags.Arg(0)
+
+	opts := lingotek.TranslationOptions{WorkflowId: *workflowId}
+	translation, err := c.api.AddTranslationWithOptionsContext(ctx, &document, flags.Arg(1), &opts)
+	if err != nil {
+		return err
+	}
+
+	return c.out.message(translation, "requested %s translation of %s", flags.Arg(1), document.Property.Id)
+}
+
+func (c *cli) listTranslations(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("translations list", flag.ContinueOnError)
+	if err := c.parse(flags, args, 1); err != nil {
+		return err
+	}
+
+	document := lingotek.Document{}
+	document.Property.Id = flags.Arg(0)
+
+	translations, err := c.api.TranslationsPager(&document, nil).Collect(ctx)
+	if err != nil {
+		return err
+	}
+
+	var rows [][]string
+	for _, translation := range translations {
+		phase := ""
+		for _, p := range translation.Phases {
+			if !p.IsComplete() {
+				phase = p.Property.Name
+				break
+			}
+		}
+
+		rows = append(rows, []string{
+			translation.Property.LocaleCode,
+			strconv.Itoa(translation.Property.PercentComplete) + "%",
+			phase,
+			formatDate(translation.Property.DueDate),
+		})
+	}
+
+	return c.out.table(translations, []string{"LOCALE", "PROGRESS", "PHASE", "DUE"}, rows)
+}
+
+func (c *cli) downloadTranslation(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("translations download", flag.ContinueOnError)
+	output := flags.String("o", "", "write to this file instead of standard output")
+	source := flags.String("validate", "", "check the translation against this source file, and only write it if it passes")
+	if err := c.parse(flags, args, 2); err != nil {
+		return err
+	}
+
+	document := lingotek.Document{}
+	document.Property.Id = flags.Arg(0)
+
+	if *source != "" {
+		return c.downloadValidated(ctx, &document, flags.Arg(1), *source, *output)
+	}
+
+	if *output == "" {
+		_, err := c.api.GetTranslatedDocumentContext(ctx, &document, flags.Arg(1), c.out.w)
+		return err
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.api.GetTranslatedDocumentContext(ctx, &document, flags.Arg(1), file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(*output)
+	}
+
+	return err
+}
+
+// downloadValidated downloads a translation and writes it only if it
+// passes validation against the source file.
+func (c *cli) downloadValidated(ctx context.Context, document *lingotek.Document, localeCode, sourcePath, output string) error {
+	content, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return err
+	}
+
+	// Bundles may be nested under the source locale
+	current, err := c.api.GetDocumentContext(ctx, document.Property.Id)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	_, err = c.api.GetTranslatedDocumentContext(ctx, document, localeCode, &buf)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(sourcePath)
+	report, err := validate.Files(
+		validate.File{Name: name, Locale: current.Locale.Property.Code, Content: content},
+		validate.File{Name: name, Locale: localeCode, Content: buf.Bytes()},
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, issue := range report.Warnings() {
+		fmt.Fprintln(c.stderr, "warning:", issue)
+	}
+
+	if output != "" {
+		return validate.WriteFile(output, buf.Bytes(), report)
+	}
+
+	if err := report.Err(); err != nil {
+		return err
+	}
+
+	_, err = buf.WriteTo(c.out.w)
+	return err
+}
+
+func (c *cli) listLocales(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("locales list", flag.ContinueOnError)
+	if err := c.parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	locales, err := c.api.LocalesPager(&lingotek.ListOptions{PageSize: 100}).Collect(ctx)
+	if err != nil {
+		return err
+	}
+
+	var rows [][]string
+	for _, locale := range locales {
+		rows = append(rows, []string{locale.Property.Code, locale.Property.Title})
+	}
+
+	return c.out.table(locales, []string{"CODE", "TITLE"}, rows)
+}
+
+func formatDate(t lingotek.LingoTime) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format("2006-01-02")
+}
@@ -0,0 +1,173 @@
+// Command lingotek performs everyday Lingotek operations from the shell.
+//
+// The access token is read from the LINGOTEK_TOKEN environment variable,
+// or from the "token" field of a JSON config file, by default
+// $XDG_CONFIG_HOME/lingotek/config.json. The config file may also set
+// "base_url", which the -production and -base-url flags override.
+//
+// Usage:
+//
+//	lingotek [-json] [-production] [-base-url URL] <resource> <action> [flags] [args]
+//
+// Run lingotek without arguments for the list of commands.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	lingotek "github.com/CuriousLLC/Lingotek"
+)
+
+const usage = `usage: lingotek [-json] [-production] [-base-url URL] <command> [flags] [args]
+
+commands:
+  communities list
+  projects list -community ID
+  documents list [-project ID] [-title TEXT] [-limit N]
+  documents get DOCUMENT_ID
+  documents upload -project ID -locale CODE [-title TITLE] [-format FORMAT] FILE
+  documents status DOCUMENT_ID
+  documents delete DOCUMENT_ID
+  translations add [-workflow ID] DOCUMENT_ID LOCALE
+  translations list DOCUMENT_ID
+  translations download [-o FILE] [-validate SOURCE_FILE] DOCUMENT_ID LOCALE
+  locales list
+`
+
+var errUsage = errors.New("invalid usage")
+
+type config struct {
+	Token   string `json:"token"`
+	BaseURL string `json:"base_url"`
+}
+
+func main() {
+	// Ctrl-C cancels the running request
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := run(ctx, os.Args[1:], os.Getenv, os.Stdout, os.Stderr)
+	stop()
+	if err == errUsage {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "lingotek:", err)
+		os.Exit(1)
+	}
+}
+
+// run executes one command. getenv and the writers are parameters so that
+// tests don't depend on the real environment.
+func run(ctx context.Context, args []string, getenv func(string) string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("lingotek", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { fmt.Fprint(stderr, usage) }
+
+	jsonOutput := flags.Bool("json", false, "print JSON instead of a table")
+	production := flags.Bool("production", false, "use the production API instead of the sandbox")
+	baseURL := flags.String("base-url", "", "API base URL, overriding -production")
+	configPath := flags.String("config", "", "config file path")
+
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+
+	if flags.NArg() < 2 {
+		return errUsage
+	}
+
+	conf, err := loadConfig(*configPath, getenv)
+	if err != nil {
+		return err
+	}
+
+	if conf.Token == "" {
+		return errors.New("no access token, set LINGOTEK_TOKEN or the config file's token")
+	}
+
+	c := cli{
+		api:    newAPI(conf, *production, *baseURL),
+		out:    newPrinter(stdout, *jsonOutput),
+		stderr: stderr,
+	}
+
+	command, ok := commands[flags.Arg(0)+" "+flags.Arg(1)]
+	if !ok {
+		return errUsage
+	}
+
+	return command(&c, ctx, flags.Args()[2:])
+}
+
+// newAPI creates the client. The -base-url and -production flags win
+// over the base URL of the config file and the environment.
+func newAPI(conf config, production bool, baseURL string) *lingotek.Lingotek {
+	options := []lingotek.Option{
+		lingotek.WithUserAgent("lingotek-cli"),
+		lingotek.WithRetry(lingotek.DefaultRetryPolicy),
+	}
+
+	switch {
+	case baseURL != "":
+		options = append(options, lingotek.WithBaseURL(baseURL))
+	case production:
+		options = append(options, lingotek.WithProduction())
+	case conf.BaseURL != "":
+		options = append(options, lingotek.WithBaseURL(conf.BaseURL))
+	}
+
+	return lingotek.NewApi(conf.Token, http.DefaultClient, options...)
+}
+
+// loadConfig reads the config file, if there is one, and lets the
+// environment override it.
+func loadConfig(path string, getenv func(string) string) (config, error) {
+	var conf config
+
+	explicit := path != ""
+	if path == "" {
+		path = getenv("LINGOTEK_CONFIG")
+		explicit = path != ""
+	}
+	if path == "" {
+		dir := getenv("XDG_CONFIG_HOME")
+		if dir == "" {
+			if home := getenv("HOME"); home != "" {
+				dir = filepath.Join(home, ".config")
+			}
+		}
+		if dir != "" {
+			path = filepath.Join(dir, "lingotek", "config.json")
+		}
+	}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			err = json.Unmarshal(data, &conf)
+			if err != nil {
+				return conf, fmt.Errorf("%s: %v", path, err)
+			}
+		} else if explicit || !os.IsNotExist(err) {
+			return conf, err
+		}
+	}
+
+	if token := getenv("LINGOTEK_TOKEN"); token != "" {
+		conf.Token = token
+	}
+	if baseURL := getenv("LINGOTEK_BASE_URL"); baseURL != "" {
+		conf.BaseURL = baseURL
+	}
+
+	return conf, nil
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	lingotek "github.com/CuriousLLC/Lingotek"
+	"github.com/CuriousLLC/Lingotek/validate"
+)
+
+func testServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "bearer dummyToken" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case r.URL.Path == "/community" && r.URL.Query().Get("offset") == "10":
+			http.ServeFile(w, r, "../../test_data/test_communities_justone.json")
+		case r.URL.Path == "/community":
+			http.ServeFile(w, r, "../../test_data/test_communitys.json")
+		case r.URL.Path == "/document/12345/content":
+			io.WriteString(w, "Vamos a la zapatería")
+		case r.URL.Path == "/document/67890/content":
+			io.WriteString(w, `{"hello": "Hola"}`)
+		case r.URL.Path == "/document/12345" || r.URL.Path == "/document/67890":
+			http.ServeFile(w, r, "../../test_data/document.json")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func runTest(t *testing.T, server *httptest.Server, args ...string) (string, error) {
+	return runTestContext(t, context.Background(), server, args...)
+}
+
+func runTestContext(t *testing.T, ctx context.Context, server *httptest.Server, args ...string) (string, error) {
+	env := map[string]string{
+		"LINGOTEK_TOKEN":    "dummyToken",
+		"LINGOTEK_BASE_URL": server.URL,
+		"LINGOTEK_CONFIG":   "",
+		"HOME":              t.TempDir(),
+	}
+	getenv := func(key string) string { return env[key] }
+
+	var stdout, stderr bytes.Buffer
+	err := run(ctx, args, getenv, &stdout, &stderr)
+	return stdout.String(), err
+}
+
+func TestCommunitiesList(t *testing.T) {
+	server := testServer(t)
+	defer server.Close()
+
+	out, err := runTest(t, server, "communities", "list")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 12 {
+		t.Errorf("Expected a header and 11 rows, got %d lines", len(lines))
+	}
+
+	if !strings.HasPrefix(lines[0], "ID") || !strings.Contains(out, "Blah blah community") {
+		t.Errorf("Unexpected table:\n%s", out)
+	}
+
+	out, err = runTest(t, server, "-json", "communities", "list")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var communities []map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &communities); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(communities) != 11 {
+		t.Errorf("Expected 11 communities, got %d", len(communities))
+	}
+}
+
+func TestDocuments(t *testing.T) {
+	server := testServer(t)
+	defer server.Close()
+
+	out, err := runTest(t, server, "documents", "get", "12345")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out, "My Test") {
+		t.Errorf("Expected the document title, got:\n%s", out)
+	}
+
+	out, err = runTest(t, server, "translations", "download", "12345", "es-ES")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out != "Vamos a la zapatería" {
+		t.Errorf("Expected the translated content, got %q", out)
+	}
+
+	if _, err := runTest(t, server, "documents", "get"); err != errUsage {
+		t.Errorf("Expected errUsage, got %v", err)
+	}
+
+	if _, err := runTest(t, server, "documents", "get", "missing"); err == nil {
+		t.Error("Expected an error for a missing document")
+	}
+}
+
+func TestCanceled(t *testing.T) {
+	server := testServer(t)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := runTestContext(t, ctx, server, "communities", "list"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestBaseURL(t *testing.T) {
+	conf := config{Token: "dummyToken", BaseURL: "http://localhost:8080/"}
+	tests := []struct {
+		production bool
+		baseURL    string
+		expected   string
+	}{
+		{false, "", "http://localhost:8080/"},
+		{true, "", lingotek.ProductionURL},
+		{true, "http://localhost:9090/", "http://localhost:9090/"},
+		{false, "http://localhost:9090/", "http://localhost:9090/"},
+	}
+
+	for _, test := range tests {
+		api := newAPI(conf, test.production, test.baseURL)
+		if api.BaseURL() != test.expected {
+			t.Errorf("-production=%v -base-url=%q: expected %s, got %s", test.production, test.baseURL, test.expected, api.BaseURL())
+		}
+	}
+
+	if api := newAPI(config{Token: "dummyToken"}, false, ""); api.BaseURL() != lingotek.SandboxURL {
+		t.Errorf("Expected the sandbox by default, got %s", api.BaseURL())
+	}
+}
+
+func TestDownloadValidate(t *testing.T) {
+	server := testServer(t)
+	defer server.Close()
+
+	dir := t.TempDir()
+	source := filepath.Join(dir, "en.json")
+	output := filepath.Join(dir, "es.json")
+	if err := os.WriteFile(source, []byte(`{"hello": "Hello, %s"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The translation dropped the placeholder
+	_, err := runTest(t, server, "translations", "download", "-validate", source, "-o", output, "67890", "es-ES")
+	var validationErr *validate.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected a ValidationError, got %v", err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("Expected the broken translation not to be written, got %v", err)
+	}
+
+	if err := os.WriteFile(source, []byte(`{"hello": "Hello"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := runTest(t, server, "translations", "download", "-validate", source, "67890", "es-ES")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != `{"hello": "Hola"}` {
+		t.Errorf("Expected the translated content, got %q", out)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// printer writes results either as a table or as indented JSON.
+type printer struct {
+	w    io.Writer
+	json bool
+}
+
+func newPrinter(w io.Writer, jsonOutput bool) *printer {
+	return &printer{w, jsonOutput}
+}
+
+// table prints rows under header, unless JSON output was asked for, in
+// which case value is printed instead.
+func (p *printer) table(value interface{}, header []string, rows [][]string) error {
+	if p.json {
+		return p.value(value)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+func (p *printer) value(value interface{}) error {
+	encoder := json.NewEncoder(p.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// message prints a line of text, or value as JSON.
+func (p *printer) message(value interface{}, format string, args ...interface{}) error {
+	if p.json {
+		return p.value(value)
+	}
+
+	_, err := fmt.Fprintf(p.w, format+"\n", args...)
+	return err
+}
@@ -1,77 +1,50 @@
 package lingotek
 
 import (
-	"encoding/json"
+	"context"
 	"net/url"
 	"strconv"
 )
 
 func (l *Lingotek) GetCommunity(communityId string) (*Community, error) {
+	return l.GetCommunityContext(context.Background(), communityId)
+}
+
+func (l *Lingotek) GetCommunityContext(ctx context.Context, communityId string) (*Community, error) {
 	var community Community
 
-	err := l.getEntity("community/"+communityId, nil, &community)
+	err := l.getEntity(ctx, "community/"+communityId, nil, &community)
 	return &community, err
 }
 
 func (l *Lingotek) GetCommunitiesPage(offset, limit int) ([]Community, error) {
+	return l.GetCommunitiesPageContext(context.Background(), offset, limit)
+}
+
+func (l *Lingotek) GetCommunitiesPageContext(ctx context.Context, offset, limit int) ([]Community, error) {
 	var communities []Community
 	v := url.Values{}
 	v.Set("offset", strconv.Itoa(offset))
 	v.Set("limit", strconv.Itoa(limit))
 
-	err := l.getEntityCollectionPage("community", &v, &communities)
+	err := l.getEntityCollectionPage(ctx, "community", &v, &communities)
 	return communities, err
 }
 
-func (l *Lingotek) ListCommunities(doneChan <-chan bool) (<-chan Community, <-chan error) {
-	resultChan := make(chan Community)
-	errChan := make(chan error, 1)
-
-	go func() {
-		defer close(resultChan)
-		defer close(errChan)
-
-		response := l.createDummyResponse("community", nil)
-
-		var communities []Community
-		var totalRead = int32(0)
-
-		for {
-			resp, err := l.getNextPage(response)
-			if err != nil {
-				if err != EndOfList {
-					errChan <- err
-				}
-				return
-			}
-
-			response = resp
-
-			if response.Properties.Size == 0 {
-				return
-			}
-
-			err = json.Unmarshal(response.Entities, &communities)
-			if err != nil {
-				errChan <- err
-				return
-			}
-
-			for i := 0; i < len(communities); i++ {
-				totalRead += 1
-				select {
-				case <-doneChan:
-					return
-				default:
-					resultChan <- communities[i]
-				}
-			}
+// CommunitiesPager returns a Pager over the communities selected by opts.
+func (l *Lingotek) CommunitiesPager(opts *ListOptions) *Pager[Community] {
+	return newPager[Community](l, "community", nil, opts)
+}
 
-			if totalRead == response.Properties.Total {
-				return
-			}
-		}
-	}()
+// ListCommunities streams every community until doneChan receives a value.
+//
+// Deprecated: use ListCommunitiesContext and cancel the context instead.
+func (l *Lingotek) ListCommunities(doneChan <-chan bool) (<-chan Community, <-chan error) {
+	return stream(context.Background(), l.CommunitiesPager(nil), doneChan)
+}
 
-	return resultChan, errChan
+// ListCommunitiesContext streams every community. Cancelling ctx stops the
+// pagination and aborts any request in flight.
+func (l *Lingotek) ListCommunitiesContext(ctx context.Context, opts *ListOptions) (<-chan Community, <-chan error) {
+	return stream(ctx, l.CommunitiesPager(opts), nil)
 }
@@ -0,0 +1,485 @@
+// Package dirsync keeps a directory of resource files in step with a
+// Lingotek project. Source files are uploaded as documents, and completed
+// translations are written back next to them, one directory per locale:
+//
+//	locales/en-US/messages.json    uploaded as document "messages.json"
+//	locales/es-ES/messages.json    its es-ES translation
+//
+// A lockfile maps every source file to its document and records content
+// hashes, so only changed files are uploaded and only new or updated
+// translations are downloaded.
+package dirsync
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	lingotek "github.com/CuriousLLC/Lingotek"
+)
+
+// API is the part of *lingotek.Lingotek a Syncer uses.
+type API interface {
+	UploadDocumentContext(ctx context.Context, upload lingotek.UploadRequest) (*lingotek.Status, error)
+	UpdateDocumentContext(ctx context.Context, document *lingotek.Document, content io.Reader, opts *lingotek.UpdateOptions) (*lingotek.Status, error)
+	DeleteDocumentContext(ctx context.Context, document *lingotek.Document) error
+	CheckStatusContext(ctx context.Context, document lingotek.Document) (*lingotek.Document, error)
+	AddTranslationContext(ctx context.Context, document *lingotek.Document, localeCode string) (*lingotek.Translation, error)
+	GetTranslationsPageContext(ctx context.Context, document *lingotek.Document, offset, limit int) ([]lingotek.Translation, error)
+	GetTranslatedDocumentContext(ctx context.Context, document *lingotek.Document, localeCode string, writer io.Writer) (int64, error)
+}
+
+// Config describes the local tree and the project it is synced with.
+// Paths are relative to Root.
+type Config struct {
+	Root          string
+	ProjectId     string
+	SourceLocale  string
+	TargetLocales []string
+	// SourceDir holds the files to upload. Defaults to "locales/{locale}"
+	// with the source locale filled in.
+	SourceDir string
+	// TargetDir is where translations are written, "{locale}" being
+	// replaced by the target locale. Defaults to "locales/{locale}".
+	TargetDir string
+	// Include limits the upload to files whose name matches one of these
+	// patterns, e.g. "*.json". Empty includes every file.
+	Include []string
+	// Format is passed to Lingotek as the format of every upload.
+	Format string
+	// Lockfile defaults to ".lingotek-lock.json".
+	Lockfile string
+	// Prune deletes the documents of source files that no longer exist.
+	// Otherwise they are only reported as missing.
+	Prune bool
+	// Validate, if set, is given every downloaded translation along with
+	// its source file, which is nil if it no longer exists. A translation
+	// it returns an error for isn't written, and is reported as invalid
+	// so that the next sync downloads it again. See validate.Files.
+	Validate func(rel, locale string, source, translation []byte) error
+}
+
+// Target is one translation of one source file.
+type Target struct {
+	Path   string
+	Locale string
+}
+
+// InvalidTarget is a downloaded translation that failed validation.
+type InvalidTarget struct {
+	Target
+	Err error
+}
+
+// Result reports what a sync did. Paths are the slash separated source
+// paths used as lockfile keys, except Downloaded which holds the local
+// paths translations were written to.
+type Result struct {
+	Uploaded   []string
+	Updated    []string
+	Unchanged  []string
+	Missing    []string
+	Deleted    []string
+	Requested  []Target
+	Pending    []Target
+	Downloaded []string
+	Invalid    []InvalidTarget
+}
+
+type Syncer struct {
+	api    API
+	config Config
+}
+
+func New(api API, config Config) *Syncer {
+	if config.TargetDir == "" {
+		config.TargetDir = "locales/{locale}"
+	}
+	if config.SourceDir == "" {
+		config.SourceDir = strings.ReplaceAll("locales/{locale}", "{locale}", config.SourceLocale)
+	}
+	if config.Lockfile == "" {
+		config.Lockfile = ".lingotek-lock.json"
+	}
+
+	return &Syncer{api, config}
+}
+
+func (s *Syncer) path(rel string) string {
+	return filepath.Join(s.config.Root, filepath.FromSlash(rel))
+}
+
+func (s *Syncer) targetPath(rel, locale string) string {
+	dir := strings.ReplaceAll(s.config.TargetDir, "{locale}", locale)
+	return s.path(path.Join(filepath.ToSlash(dir), rel))
+}
+
+// Sync pushes local changes and then pulls completed translations.
+// Translations of files uploaded by this sync aren't pulled, as they
+// can't be finished yet.
+func (s *Syncer) Sync(ctx context.Context) (*Result, error) {
+	var result Result
+
+	lock, err := LoadLockfile(s.path(s.config.Lockfile))
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.push(ctx, lock, &result)
+	if err == nil {
+		changed := make(map[string]bool)
+		for _, rel := range append(result.Uploaded, result.Updated...) {
+			changed[rel] = true
+		}
+		err = s.pull(ctx, lock, changed, &result)
+	}
+
+	return &result, s.save(lock, err)
+}
+
+// Push uploads new and changed source files and requests their
+// translations.
+func (s *Syncer) Push(ctx context.Context) (*Result, error) {
+	var result Result
+
+	lock, err := LoadLockfile(s.path(s.config.Lockfile))
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.push(ctx, lock, &result)
+	return &result, s.save(lock, err)
+}
+
+// Pull downloads every completed translation that is newer than the
+// local copy.
+func (s *Syncer) Pull(ctx context.Context) (*Result, error) {
+	var result Result
+
+	lock, err := LoadLockfile(s.path(s.config.Lockfile))
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.pull(ctx, lock, nil, &result)
+	return &result, s.save(lock, err)
+}
+
+// save writes the lockfile even after a failed sync, so the work that did
+// succeed isn't repeated. The sync's error takes precedence.
+func (s *Syncer) save(lock *Lockfile, err error) error {
+	saveErr := lock.Save(s.path(s.config.Lockfile))
+	if err != nil {
+		return err
+	}
+
+	return saveErr
+}
+
+// sources returns the slash separated paths of the files to upload,
+// relative to the source directory.
+func (s *Syncer) sources() ([]string, error) {
+	var sources []string
+	root := s.path(s.config.SourceDir)
+	lockfile := s.path(s.config.Lockfile)
+
+	err := filepath.WalkDir(root, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if strings.HasPrefix(entry.Name(), ".") && p != root {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if entry.IsDir() || p == lockfile || !s.included(entry.Name()) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+
+		sources = append(sources, filepath.ToSlash(rel))
+		return nil
+	})
+
+	sort.Strings(sources)
+	return sources, err
+}
+
+func (s *Syncer) included(name string) bool {
+	if len(s.config.Include) == 0 {
+		return true
+	}
+
+	for _, pattern := range s.config.Include {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Syncer) push(ctx context.Context, lock *Lockfile, result *Result) error {
+	sources, err := s.sources()
+	if err != nil {
+		return err
+	}
+
+	present := make(map[string]bool)
+	for _, rel := range sources {
+		present[rel] = true
+
+		err = s.pushFile(ctx, lock, rel, result)
+		if err != nil {
+			return err
+		}
+
+		err = s.requestTranslations(ctx, lock.Documents[rel], rel, result)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, rel := range sortedKeys(lock.Documents) {
+		if present[rel] {
+			continue
+		}
+
+		if !s.config.Prune {
+			result.Missing = append(result.Missing, rel)
+			continue
+		}
+
+		err = s.api.DeleteDocumentContext(ctx, lock.Documents[rel].document())
+		if err != nil && !errors.Is(err, lingotek.ErrNotFound) {
+			return err
+		}
+
+		delete(lock.Documents, rel)
+		result.Deleted = append(result.Deleted, rel)
+	}
+
+	return nil
+}
+
+func (s *Syncer) pushFile(ctx context.Context, lock *Lockfile, rel string, result *Result) error {
+	content, err := os.ReadFile(s.path(path.Join(filepath.ToSlash(s.config.SourceDir), rel)))
+	if err != nil {
+		return err
+	}
+
+	hash := hashContent(content)
+	locked, ok := lock.Documents[rel]
+
+	switch {
+	case !ok:
+		status, err := s.api.UploadDocumentContext(ctx, lingotek.UploadRequest{
+			Title:      rel,
+			LocaleCode: s.config.SourceLocale,
+			ProjectId:  s.config.ProjectId,
+			Format:     s.config.Format,
+			Filename:   path.Base(rel),
+			Reader:     bytes.NewReader(content),
+		})
+		if err != nil {
+			return err
+		}
+
+		lock.Documents[rel] = &LockedDocument{
+			DocumentId: status.Property.Id,
+			Hash:       hash,
+			Locales:    make(map[string]*LockedLocale),
+		}
+		result.Uploaded = append(result.Uploaded, rel)
+	case locked.Hash != hash:
+		opts := lingotek.UpdateOptions{Format: s.config.Format, Filename: path.Base(rel)}
+		_, err := s.api.UpdateDocumentContext(ctx, locked.document(), bytes.NewReader(content), &opts)
+		if err != nil {
+			return err
+		}
+
+		// The translations' SourceHash no longer matches, which marks
+		// them for download once Lingotek has redone them
+		locked.Hash = hash
+		result.Updated = append(result.Updated, rel)
+	default:
+		result.Unchanged = append(result.Unchanged, rel)
+	}
+
+	return nil
+}
+
+// requestTranslations requests every target locale not requested yet.
+// Lingotek refuses translations of a document it is still importing, so
+// those are left for a later push.
+func (s *Syncer) requestTranslations(ctx context.Context, locked *LockedDocument, rel string, result *Result) error {
+	if locked.Locales == nil {
+		locked.Locales = make(map[string]*LockedLocale)
+	}
+
+	imported := false
+	for _, locale := range s.config.TargetLocales {
+		state, ok := locked.Locales[locale]
+		if !ok {
+			state = &LockedLocale{}
+			locked.Locales[locale] = state
+		}
+
+		if state.Requested {
+			continue
+		}
+
+		if !imported {
+			_, err := s.api.CheckStatusContext(ctx, *locked.document())
+			if errors.Is(err, lingotek.ErrNotFound) || errors.Is(err, lingotek.ErrImporting) {
+				result.Pending = append(result.Pending, Target{rel, locale})
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			imported = true
+		}
+
+		_, err := s.api.AddTranslationContext(ctx, locked.document(), locale)
+		if err != nil {
+			return err
+		}
+
+		state.Requested = true
+		result.Requested = append(result.Requested, Target{rel, locale})
+	}
+
+	return nil
+}
+
+func (s *Syncer) pull(ctx context.Context, lock *Lockfile, skip map[string]bool, result *Result) error {
+	for _, rel := range sortedKeys(lock.Documents) {
+		locked := lock.Documents[rel]
+		if skip[rel] {
+			for _, locale := range s.config.TargetLocales {
+				result.Pending = append(result.Pending, Target{rel, locale})
+			}
+			continue
+		}
+
+		translations, err := s.api.GetTranslationsPageContext(ctx, locked.document(), 0, 100)
+		if errors.Is(err, lingotek.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		complete := make(map[string]bool)
+		for _, translation := range translations {
+			if translation.IsComplete() {
+				complete[lingotek.CanonicalTag(translation.Property.LocaleCode)] = true
+			}
+		}
+
+		for _, locale := range s.config.TargetLocales {
+			if !complete[lingotek.CanonicalTag(locale)] {
+				result.Pending = append(result.Pending, Target{rel, locale})
+				continue
+			}
+
+			err = s.pullTranslation(ctx, locked, rel, locale, result)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Syncer) pullTranslation(ctx context.Context, locked *LockedDocument, rel, locale string, result *Result) error {
+	if locked.Locales == nil {
+		locked.Locales = make(map[string]*LockedLocale)
+	}
+
+	state, ok := locked.Locales[locale]
+	if !ok {
+		state = &LockedLocale{Requested: true}
+		locked.Locales[locale] = state
+	}
+
+	target := s.targetPath(rel, locale)
+	if state.SourceHash == locked.Hash && fileHash(target) == state.Hash {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	_, err := s.api.GetTranslatedDocumentContext(ctx, locked.document(), locale, &buf)
+	if err != nil {
+		return err
+	}
+
+	if s.config.Validate != nil {
+		source, _ := os.ReadFile(s.path(path.Join(filepath.ToSlash(s.config.SourceDir), rel)))
+		err = s.config.Validate(rel, locale, source, buf.Bytes())
+		if err != nil {
+			result.Invalid = append(result.Invalid, InvalidTarget{Target{rel, locale}, err})
+			return nil
+		}
+	}
+
+	err = writeFileAtomic(target, buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	state.SourceHash = locked.Hash
+	state.Hash = hashContent(buf.Bytes())
+	result.Downloaded = append(result.Downloaded, target)
+
+	return nil
+}
+
+func (d *LockedDocument) document() *lingotek.Document {
+	document := lingotek.Document{}
+	document.Property.Id = d.DocumentId
+	return &document
+}
+
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// fileHash returns the hash of a file, or "" if it can't be read.
+func fileHash(p string) string {
+	content, err := os.ReadFile(p)
+	if err != nil {
+		return ""
+	}
+
+	return hashContent(content)
+}
+
+func sortedKeys(documents map[string]*LockedDocument) []string {
+	keys := make([]string, 0, len(documents))
+	for key := range documents {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,351 @@
+package dirsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	lingotek "github.com/CuriousLLC/Lingotek"
+	"github.com/CuriousLLC/Lingotek/validate"
+)
+
+// fakeAPI keeps documents in memory. A translation is complete once its
+// locale is listed in done, and its content is taken from translated if
+// set there.
+type fakeAPI struct {
+	documents  map[string]string
+	requested  map[string][]string
+	done       map[string]bool
+	translated map[string]string
+	importing  error
+	uploads    int
+	updates    int
+}
+
+func newFakeAPI() *fakeAPI {
+	return &fakeAPI{
+		documents:  make(map[string]string),
+		requested:  make(map[string][]string),
+		done:       make(map[string]bool),
+		translated: make(map[string]string),
+	}
+}
+
+func (f *fakeAPI) UploadDocumentContext(ctx context.Context, upload lingotek.UploadRequest) (*lingotek.Status, error) {
+	content, err := io.ReadAll(upload.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	f.uploads++
+	id := fmt.Sprintf("doc-%d", f.uploads)
+	f.documents[id] = string(content)
+
+	status := lingotek.Status{}
+	status.Property.Id = id
+	return &status, nil
+}
+
+func (f *fakeAPI) UpdateDocumentContext(ctx context.Context, document *lingotek.Document, content io.Reader, opts *lingotek.UpdateOptions) (*lingotek.Status, error) {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return nil, err
+	}
+
+	f.updates++
+	f.documents[document.Property.Id] = string(data)
+	return &lingotek.Status{}, nil
+}
+
+func (f *fakeAPI) DeleteDocumentContext(ctx context.Context, document *lingotek.Document) error {
+	delete(f.documents, document.Property.Id)
+	return nil
+}
+
+func (f *fakeAPI) CheckStatusContext(ctx context.Context, document lingotek.Document) (*lingotek.Document, error) {
+	if f.importing != nil {
+		return nil, f.importing
+	}
+
+	return &document, nil
+}
+
+func (f *fakeAPI) AddTranslationContext(ctx context.Context, document *lingotek.Document, localeCode string) (*lingotek.Translation, error) {
+	id := document.Property.Id
+	f.requested[id] = append(f.requested[id], localeCode)
+	return &lingotek.Translation{}, nil
+}
+
+func (f *fakeAPI) GetTranslationsPageContext(ctx context.Context, document *lingotek.Document, offset, limit int) ([]lingotek.Translation, error) {
+	var translations []lingotek.Translation
+	for _, locale := range f.requested[document.Property.Id] {
+		translation := lingotek.Translation{}
+		translation.Property.LocaleCode = locale
+		if f.done[locale] {
+			translation.Property.PercentComplete = 100
+		}
+		translations = append(translations, translation)
+	}
+
+	return translations, nil
+}
+
+func (f *fakeAPI) GetTranslatedDocumentContext(ctx context.Context, document *lingotek.Document, localeCode string, writer io.Writer) (int64, error) {
+	content, ok := f.documents[document.Property.Id]
+	if !ok {
+		return 0, errors.New("no such document")
+	}
+
+	if translated, ok := f.translated[localeCode]; ok {
+		content = translated
+	} else {
+		content = localeCode + ": " + content
+	}
+
+	n, err := io.WriteString(writer, content)
+	return int64(n), err
+}
+
+func writeFile(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestSync(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "locales/en-US/messages.json"), `{"hello": "Hello"}`)
+	writeFile(t, filepath.Join(root, "locales/en-US/admin/errors.json"), `{"oops": "Oops"}`)
+	writeFile(t, filepath.Join(root, "locales/en-US/README.md"), "not a resource")
+
+	api := newFakeAPI()
+	syncer := New(api, Config{
+		Root:          root,
+		ProjectId:     "project",
+		SourceLocale:  "en-US",
+		TargetLocales: []string{"es-ES", "de-DE"},
+		Include:       []string{"*.json"},
+	})
+	ctx := context.Background()
+
+	result, err := syncer.Sync(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"admin/errors.json", "messages.json"}; !reflect.DeepEqual(result.Uploaded, expected) {
+		t.Errorf("Expected uploads %v, got %v", expected, result.Uploaded)
+	}
+	if len(result.Requested) != 4 {
+		t.Errorf("Expected 4 translation requests, got %v", result.Requested)
+	}
+	if len(result.Downloaded) != 0 {
+		t.Errorf("Expected nothing downloaded on first sync, got %v", result.Downloaded)
+	}
+
+	// Only es-ES is done, and nothing changed locally
+	api.done["es-ES"] = true
+	result, err = syncer.Sync(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if api.uploads != 2 || api.updates != 0 || len(result.Unchanged) != 2 {
+		t.Errorf("Expected unchanged files to be skipped, got %d uploads and %d updates", api.uploads, api.updates)
+	}
+	if len(result.Requested) != 0 {
+		t.Errorf("Expected no new translation requests, got %v", result.Requested)
+	}
+	if len(result.Downloaded) != 2 || len(result.Pending) != 2 {
+		t.Errorf("Expected 2 downloads and 2 pending, got %v and %v", result.Downloaded, result.Pending)
+	}
+
+	translated := filepath.Join(root, "locales/es-ES/messages.json")
+	if content := readFile(t, translated); content != `es-ES: {"hello": "Hello"}` {
+		t.Errorf("Unexpected translation %q", content)
+	}
+
+	// A pull without changes downloads nothing
+	result, err = syncer.Pull(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Downloaded) != 0 {
+		t.Errorf("Expected up to date translations to be skipped, got %v", result.Downloaded)
+	}
+
+	// Changing a source file updates its document, and its translations
+	// are downloaded again by the next pull
+	writeFile(t, filepath.Join(root, "locales/en-US/messages.json"), `{"hello": "Hi"}`)
+	result, err = syncer.Push(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"messages.json"}; api.updates != 1 || !reflect.DeepEqual(result.Updated, expected) {
+		t.Errorf("Expected messages.json to be updated, got %v", result.Updated)
+	}
+
+	result, err = syncer.Pull(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{translated}; !reflect.DeepEqual(result.Downloaded, expected) {
+		t.Errorf("Expected %v to be downloaded, got %v", expected, result.Downloaded)
+	}
+	if content := readFile(t, translated); content != `es-ES: {"hello": "Hi"}` {
+		t.Errorf("Unexpected translation %q", content)
+	}
+
+	lock, err := LoadLockfile(filepath.Join(root, ".lingotek-lock.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locked := lock.Documents["messages.json"]; locked == nil || locked.DocumentId != "doc-2" {
+		t.Errorf("Expected messages.json to be locked to doc-2, got %+v", locked)
+	}
+}
+
+func TestPullValidate(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "locales/en-US/messages.json"), `{"hello": "Hello, {{name}}!"}`)
+
+	api := newFakeAPI()
+	api.translated["es-ES"] = `{"hello": "¡Hola, {{name}}!"}`
+	api.translated["de-DE"] = `{"hello": "Hallo!"}`
+	syncer := New(api, Config{
+		Root:          root,
+		SourceLocale:  "en-US",
+		TargetLocales: []string{"es-ES", "de-DE"},
+		Validate: func(rel, locale string, source, translation []byte) error {
+			report, err := validate.Files(
+				validate.File{Name: rel, Locale: "en-US", Content: source},
+				validate.File{Name: rel, Locale: locale, Content: translation},
+				nil,
+			)
+			if err != nil {
+				return err
+			}
+			return report.Err()
+		},
+	})
+	ctx := context.Background()
+
+	if _, err := syncer.Push(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	api.done["es-ES"] = true
+	api.done["de-DE"] = true
+	result, err := syncer.Pull(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{filepath.Join(root, "locales/es-ES/messages.json")}; !reflect.DeepEqual(result.Downloaded, expected) {
+		t.Errorf("Expected %v to be downloaded, got %v", expected, result.Downloaded)
+	}
+
+	var validationErr *validate.ValidationError
+	if len(result.Invalid) != 1 || result.Invalid[0].Locale != "de-DE" || !errors.As(result.Invalid[0].Err, &validationErr) {
+		t.Fatalf("Expected the de-DE translation to be invalid, got %v", result.Invalid)
+	}
+	if _, err := os.Stat(filepath.Join(root, "locales/de-DE/messages.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected the invalid translation not to be written, got %v", err)
+	}
+
+	// The invalid translation is downloaded again once it is fixed
+	api.translated["de-DE"] = `{"hello": "Hallo, {{name}}!"}`
+	result, err = syncer.Pull(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{filepath.Join(root, "locales/de-DE/messages.json")}; !reflect.DeepEqual(result.Downloaded, expected) || len(result.Invalid) != 0 {
+		t.Errorf("Expected %v to be downloaded, got %v and %v invalid", expected, result.Downloaded, result.Invalid)
+	}
+}
+
+func TestPushAccepted(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "src/a.json"), "a")
+
+	// Lingotek answers 202 while the document is importing
+	api := newFakeAPI()
+	api.importing = lingotek.ErrImporting
+	config := Config{
+		Root:          root,
+		SourceLocale:  "en-US",
+		SourceDir:     "src",
+		TargetLocales: []string{"fr-FR"},
+	}
+
+	result, err := New(api, config).Push(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Requested) != 0 || len(result.Pending) != 1 {
+		t.Errorf("Expected the translation to be pending, got %v", result.Pending)
+	}
+}
+
+func TestPushMissingAndImporting(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "src/a.json"), "a")
+	writeFile(t, filepath.Join(root, "src/b.json"), "b")
+
+	api := newFakeAPI()
+	api.importing = &lingotek.APIError{StatusCode: 404}
+	config := Config{
+		Root:          root,
+		SourceLocale:  "en-US",
+		SourceDir:     "src",
+		TargetLocales: []string{"fr-FR"},
+	}
+	ctx := context.Background()
+
+	result, err := New(api, config).Push(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Requested) != 0 || len(result.Pending) != 2 {
+		t.Errorf("Expected translations of importing documents to be pending, got %v", result.Pending)
+	}
+
+	api.importing = nil
+	os.Remove(filepath.Join(root, "src/b.json"))
+
+	result, err = New(api, config).Push(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Requested) != 1 {
+		t.Errorf("Expected the pending translation to be requested, got %v", result.Requested)
+	}
+	if expected := []string{"b.json"}; !reflect.DeepEqual(result.Missing, expected) {
+		t.Errorf("Expected %v to be missing, got %v", expected, result.Missing)
+	}
+
+	config.Prune = true
+	result, err = New(api, config).Push(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"b.json"}; !reflect.DeepEqual(result.Deleted, expected) || len(api.documents) != 1 {
+		t.Errorf("Expected %v to be deleted, got %v", expected, result.Deleted)
+	}
+}
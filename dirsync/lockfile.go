@@ -0,0 +1,86 @@
+package dirsync
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/CuriousLLC/Lingotek/validate"
+)
+
+const lockfileVersion = 1
+
+// Lockfile remembers which document each local file was uploaded as, and
+// what was last uploaded and downloaded, so unchanged files are skipped.
+type Lockfile struct {
+	Version   int                        `json:"version"`
+	Documents map[string]*LockedDocument `json:"documents"`
+}
+
+// LockedDocument is the state of one source file, keyed in the lockfile by
+// its slash separated path relative to the source directory.
+type LockedDocument struct {
+	DocumentId string                   `json:"document_id"`
+	Hash       string                   `json:"hash"`
+	Locales    map[string]*LockedLocale `json:"locales"`
+}
+
+// LockedLocale is the state of one translation of a document.
+type LockedLocale struct {
+	// Requested is set once the translation has been requested.
+	Requested bool `json:"requested"`
+	// SourceHash is the source hash the downloaded translation belongs
+	// to. It differs from the document's Hash while a download is due.
+	SourceHash string `json:"source_hash,omitempty"`
+	// Hash is the hash of the downloaded translation.
+	Hash string `json:"hash,omitempty"`
+}
+
+// LoadLockfile reads a lockfile. A missing file yields an empty lockfile.
+func LoadLockfile(path string) (*Lockfile, error) {
+	lock := Lockfile{
+		Version:   lockfileVersion,
+		Documents: make(map[string]*LockedDocument),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &lock, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &lock)
+	if err != nil {
+		return nil, err
+	}
+
+	if lock.Documents == nil {
+		lock.Documents = make(map[string]*LockedDocument)
+	}
+
+	return &lock, nil
+}
+
+// Save writes the lockfile atomically, so an interrupted sync never leaves
+// a truncated lockfile behind.
+func (l *Lockfile) Save(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, append(data, '\n'))
+}
+
+// writeFileAtomic creates the directories leading to path, and writes it
+// with validate.WriteFile, which replaces it atomically.
+func writeFileAtomic(path string, data []byte) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	return validate.WriteFile(path, data, nil)
+}
@@ -1,66 +1,116 @@
 package lingotek
 
 import (
-	"encoding/json"
+	"context"
 	"io"
 	"net/url"
+	"strconv"
+	"time"
 )
 
-func (l *Lingotek) ListDocuments(doneChan <-chan bool) (<-chan Document, <-chan error) {
-	resultChan := make(chan Document)
-	errChan := make(chan error, 1)
-
-	go func() {
-		defer close(resultChan)
-		defer close(errChan)
-
-		response := l.createDummyResponse("document", nil)
-
-		var documents []Document
-		var totalRead = int32(0)
-
-		for {
-			resp, err := l.getNextPage(response)
-			if err != nil {
-				if err != EndOfList {
-					errChan <- err
-				}
-				return
-			}
-
-			response = resp
-
-			if response.Properties.Size == 0 {
-				return
-			}
-
-			err = json.Unmarshal(response.Entities, &documents)
-			if err != nil {
-				errChan <- err
-				return
-			}
-
-			for i := 0; i < len(documents); i++ {
-				totalRead += 1
-				select {
-				case <-doneChan:
-					return
-				default:
-					resultChan <- documents[i]
-				}
-			}
-
-			if totalRead == response.Properties.Total {
-				return
-			}
+func (l *Lingotek) GetDocumentsPage(offset, limit int) ([]Document, error) {
+	return l.GetDocumentsPageContext(context.Background(), offset, limit)
+}
+
+func (l *Lingotek) GetDocumentsPageContext(ctx context.Context, offset, limit int) ([]Document, error) {
+	var documents []Document
+	v := url.Values{}
+	v.Set("offset", strconv.Itoa(offset))
+	v.Set("limit", strconv.Itoa(limit))
+
+	err := l.getEntityCollectionPage(ctx, "document", &v, &documents)
+	return documents, err
+}
+
+// DocumentSort names a field documents can be sorted by.
+type DocumentSort string
+
+const (
+	SortByTitle      DocumentSort = "title"
+	SortByUploadDate DocumentSort = "upload_date"
+)
+
+// DocumentListOptions narrows down a document listing on the server. Zero
+// fields don't filter.
+type DocumentListOptions struct {
+	ListOptions
+	ProjectId string
+	// Title matches documents whose title contains it.
+	Title          string
+	UploadedAfter  time.Time
+	UploadedBefore time.Time
+	Extension      string
+	LocaleCode     string
+	Sort           DocumentSort
+	Descending     bool
+}
+
+// values encodes the filters as query parameters.
+func (o *DocumentListOptions) values() url.Values {
+	v := url.Values{}
+	if o == nil {
+		return v
+	}
 
+	if o.ProjectId != "" {
+		v.Set("project_id", o.ProjectId)
+	}
+	if o.Title != "" {
+		v.Set("title", o.Title)
+	}
+	// Lingotek dates are milliseconds since the epoch
+	if !o.UploadedAfter.IsZero() {
+		v.Set("upload_date_start", LingoTime{o.UploadedAfter}.param())
+	}
+	if !o.UploadedBefore.IsZero() {
+		v.Set("upload_date_end", LingoTime{o.UploadedBefore}.param())
+	}
+	if o.Extension != "" {
+		v.Set("extension", o.Extension)
+	}
+	if o.LocaleCode != "" {
+		v.Set("locale_code", o.LocaleCode)
+	}
+	if o.Sort != "" {
+		v.Set("sort", string(o.Sort))
+		if o.Descending {
+			v.Set("order", "desc")
+		} else {
+			v.Set("order", "asc")
 		}
-	}()
+	}
+
+	return v
+}
+
+// DocumentsPager returns a Pager over the documents selected by opts.
+func (l *Lingotek) DocumentsPager(opts *DocumentListOptions) *Pager[Document] {
+	var listOptions *ListOptions
+	if opts != nil {
+		listOptions = &opts.ListOptions
+	}
+
+	return newPager[Document](l, "document", opts.values(), listOptions)
+}
 
-	return resultChan, errChan
+// ListDocuments streams every document until doneChan receives a value.
+//
+// Deprecated: use ListDocumentsContext and cancel the context instead.
+func (l *Lingotek) ListDocuments(doneChan <-chan bool) (<-chan Document, <-chan error) {
+	return stream(context.Background(), l.DocumentsPager(nil), doneChan)
+}
+
+// ListDocumentsContext streams the documents selected by opts. Cancelling
+// ctx stops the pagination and aborts any request in flight.
+func (l *Lingotek) ListDocumentsContext(ctx context.Context, opts *DocumentListOptions) (<-chan Document, <-chan error) {
+	return stream(ctx, l.DocumentsPager(opts), nil)
 }
 
 func (l *Lingotek) UploadString(title, content, localeCode string, project Project) (*Status, error) {
+	return l.UploadStringContext(context.Background(), title, content, localeCode, project)
+}
+
+func (l *Lingotek) UploadStringContext(ctx context.Context, title, content, localeCode string, project Project) (*Status, error) {
 	var status Status
 	v := url.Values{}
 	v.Set("title", title)
@@ -68,11 +118,113 @@ func (l *Lingotek) UploadString(title, content, localeCode string, project Proje
 	v.Set("locale_code", localeCode)
 	v.Set("project_id", project.Property.Id)
 
-	err := l.postEntity("document", &v, &status)
+	err := l.postEntity(ctx, "document", &v, &status)
+	return &status, err
+}
+
+// UpdateOptions lists the document fields UpdateDocument changes. Empty
+// fields are left as they are.
+type UpdateOptions struct {
+	Title       string
+	CallbackUrl string
+	ExternalUrl string
+	// Format, Charset and Filename describe new content, see UploadRequest.
+	Format   string
+	Charset  string
+	Filename string
+}
+
+func (o *UpdateOptions) fields() url.Values {
+	v := url.Values{}
+	if o == nil {
+		return v
+	}
+
+	optional := map[string]string{
+		"title":        o.Title,
+		"callback_url": o.CallbackUrl,
+		"external_url": o.ExternalUrl,
+		"format":       o.Format,
+		"charset":      o.Charset,
+	}
+	for key, value := range optional {
+		if value != "" {
+			v.Set(key, value)
+		}
+	}
+
+	return v
+}
+
+func (l *Lingotek) UpdateDocument(document *Document, content io.Reader, opts *UpdateOptions) (*Status, error) {
+	return l.UpdateDocumentContext(context.Background(), document, content, opts)
+}
+
+// UpdateDocumentContext replaces the content and metadata of an existing
+// document, keeping its translations. content may be nil to change only
+// the fields in opts; otherwise it is streamed and the request isn't retried.
+func (l *Lingotek) UpdateDocumentContext(ctx context.Context, document *Document, content io.Reader, opts *UpdateOptions) (*Status, error) {
+	if document.Property.Id == "" {
+		return nil, IdRequired
+	}
+
+	var status Status
+	route := "document/" + document.Property.Id
+	fields := opts.fields()
+
+	if content == nil {
+		if len(fields) == 0 {
+			return nil, ContentRequired
+		}
+
+		err := l.patchEntity(ctx, route, &fields, &status)
+		return &status, err
+	}
+
+	filename := document.Property.Name
+	if opts != nil && opts.Filename != "" {
+		filename = opts.Filename
+	}
+
+	err := l.sendMultipart(ctx, route, "PATCH", fields, filename, content, &status)
 	return &status, err
 }
 
+// DeleteDocument removes a document and all of its translations. A document
+// that doesn't exist yields an *APIError matching ErrNotFound.
+func (l *Lingotek) DeleteDocument(document *Document) error {
+	return l.DeleteDocumentContext(context.Background(), document)
+}
+
+func (l *Lingotek) DeleteDocumentContext(ctx context.Context, document *Document) error {
+	if document.Property.Id == "" {
+		return IdRequired
+	}
+
+	return l.deleteEntity(ctx, "document/"+document.Property.Id, nil)
+}
+
 func (l *Lingotek) AddTranslation(document *Document, localeCode string) (*Translation, error) {
+	return l.AddTranslationContext(context.Background(), document, localeCode)
+}
+
+func (l *Lingotek) AddTranslationContext(ctx context.Context, document *Document, localeCode string) (*Translation, error) {
+	return l.AddTranslationWithOptionsContext(ctx, document, localeCode, nil)
+}
+
+// TranslationOptions customizes a translation requested with
+// AddTranslationWithOptions. Zero fields use the project's settings.
+type TranslationOptions struct {
+	// WorkflowId overrides the project's workflow for this locale only.
+	WorkflowId string
+	DueDate    time.Time
+}
+
+func (l *Lingotek) AddTranslationWithOptions(document *Document, localeCode string, opts *TranslationOptions) (*Translation, error) {
+	return l.AddTranslationWithOptionsContext(context.Background(), document, localeCode, opts)
+}
+
+func (l *Lingotek) AddTranslationWithOptionsContext(ctx context.Context, document *Document, localeCode string, opts *TranslationOptions) (*Translation, error) {
 	var translation Translation
 
 	if document.Property.Id == "" {
@@ -81,12 +233,24 @@ func (l *Lingotek) AddTranslation(document *Document, localeCode string) (*Trans
 
 	v := url.Values{}
 	v.Set("locale_code", localeCode)
+	if opts != nil {
+		if opts.WorkflowId != "" {
+			v.Set("workflow_id", opts.WorkflowId)
+		}
+		if !opts.DueDate.IsZero() {
+			v.Set("due_date", LingoTime{opts.DueDate}.param())
+		}
+	}
 
-	err := l.postEntity("document/"+document.Property.Id+"/translation", &v, &translation)
+	err := l.postEntity(ctx, "document/"+document.Property.Id+"/translation", &v, &translation)
 	return &translation, err
 }
 
 func (l *Lingotek) GetTranslatedDocument(document *Document, localeCode string, writer io.Writer) (int64, error) {
+	return l.GetTranslatedDocumentContext(context.Background(), document, localeCode, writer)
+}
+
+func (l *Lingotek) GetTranslatedDocumentContext(ctx context.Context, document *Document, localeCode string, writer io.Writer) (int64, error) {
 	if document.Property.Id == "" {
 		return 0, IdRequired
 	}
@@ -94,70 +258,89 @@ func (l *Lingotek) GetTranslatedDocument(document *Document, localeCode string,
 	v := url.Values{}
 	v.Set("locale_code", localeCode)
 
-	return l.downloadContent("document/"+document.Property.Id+"/content", &v, writer)
+	return l.downloadContent(ctx, "document/"+document.Property.Id+"/content", &v, writer)
+}
+
+func (l *Lingotek) GetTranslationsPage(document *Document, offset, limit int) ([]Translation, error) {
+	return l.GetTranslationsPageContext(context.Background(), document, offset, limit)
+}
+
+func (l *Lingotek) GetTranslationsPageContext(ctx context.Context, document *Document, offset, limit int) ([]Translation, error) {
+	if document.Property.Id == "" {
+		return nil, IdRequired
+	}
+
+	var translations []Translation
+	v := url.Values{}
+	v.Set("offset", strconv.Itoa(offset))
+	v.Set("limit", strconv.Itoa(limit))
+
+	err := l.getEntityCollectionPage(ctx, "document/"+document.Property.Id+"/translation", &v, &translations)
+	return translations, err
+}
+
+// DeleteTranslation removes the localeCode translation of document. A
+// translation that doesn't exist yields an *APIError matching ErrNotFound.
+func (l *Lingotek) DeleteTranslation(document *Document, localeCode string) error {
+	return l.DeleteTranslationContext(context.Background(), document, localeCode)
+}
+
+func (l *Lingotek) DeleteTranslationContext(ctx context.Context, document *Document, localeCode string) error {
+	if document.Property.Id == "" {
+		return IdRequired
+	}
+
+	v := url.Values{}
+	v.Set("locale_code", localeCode)
+
+	return l.deleteEntity(ctx, "document/"+document.Property.Id+"/translation", &v)
+}
+
+// TranslationsPager returns a Pager over the translations of document
+// selected by opts.
+func (l *Lingotek) TranslationsPager(document *Document, opts *ListOptions) *Pager[Translation] {
+	return newPager[Translation](l, "document/"+document.Property.Id+"/translation", nil, opts)
 }
 
+// ListTranslations streams every translation of document until doneChan
+// receives a value.
+//
+// Deprecated: use ListTranslationsContext and cancel the context instead.
 func (l *Lingotek) ListTranslations(document *Document, doneChan <-chan bool) (<-chan Translation, <-chan error) {
-	resultChan := make(chan Translation)
-	errChan := make(chan error, 1)
-
-	go func() {
-		defer close(resultChan)
-		defer close(errChan)
-
-		response := l.createDummyResponse("document/"+document.Property.Id+"/translation", nil)
-
-		var translations []Translation
-
-		for {
-			resp, err := l.getNextPage(response)
-			if err != nil {
-				if err != EndOfList {
-					errChan <- err
-				}
-				return
-			}
-
-			response = resp
-
-			if response.Properties.Size == 0 {
-				return
-			}
-
-			err = json.Unmarshal(response.Entities, &translations)
-			if err != nil {
-				errChan <- err
-				return
-			}
-
-			for i := 0; i < len(translations); i++ {
-				select {
-				case <-doneChan:
-					return
-				default:
-					resultChan <- translations[i]
-				}
-			}
-		}
-	}()
+	return stream(context.Background(), l.TranslationsPager(document, nil), doneChan)
+}
 
-	return resultChan, errChan
+// ListTranslationsContext streams every translation of document. Cancelling
+// ctx stops the pagination and aborts any request in flight.
+func (l *Lingotek) ListTranslationsContext(ctx context.Context, document *Document, opts *ListOptions) (<-chan Translation, <-chan error) {
+	return stream(ctx, l.TranslationsPager(document, opts), nil)
 }
 
+// CheckStatus fetches the current status of doc. Right after an upload it
+// returns ErrImporting, or an *APIError matching ErrNotFound, until
+// Lingotek has imported the document.
 func (l *Lingotek) CheckStatus(doc Document) (*Document, error) {
+	return l.CheckStatusContext(context.Background(), doc)
+}
+
+func (l *Lingotek) CheckStatusContext(ctx context.Context, doc Document) (*Document, error) {
 	if doc.Property.Id == "" {
 		return nil, IdRequired
 	}
 
 	var document Document
 
-	err := l.getEntity("document/"+doc.Property.Id, nil, &document)
+	err := l.getImporting(ctx, "document/"+doc.Property.Id, nil, &document)
 	return &document, err
 }
 
 func (l *Lingotek) GetDocument(id string) (*Document, error) {
+	return l.GetDocumentContext(context.Background(), id)
+}
+
+func (l *Lingotek) GetDocumentContext(ctx context.Context, id string) (*Document, error) {
 	var document Document
 
-	err := l.getEntity("document/"+id, nil, &document)
+	err := l.getEntity(ctx, "document/"+id, nil, &document)
 	return &document, err
 }
@@ -0,0 +1,79 @@
+package lingotek
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+var ErrNotFound = errors.New("Resource not found")
+var ErrUnauthorized = errors.New("Not authorized")
+var ErrForbidden = errors.New("Access forbidden")
+var ErrRateLimited = errors.New("Rate limit exceeded")
+
+// ErrImporting is returned when Lingotek answers 202 Accepted for a document
+// it hasn't finished importing.
+var ErrImporting = errors.New("Document is still being imported")
+
+// APIError is returned whenever Lingotek answers with a 4xx or 5xx status.
+// It matches ErrNotFound, ErrUnauthorized, ErrForbidden, ErrRateLimited and
+// ServerError with errors.Is, depending on the status code.
+type APIError struct {
+	StatusCode int
+	Status     string
+	Method     string
+	Route      string
+	RequestID  string
+	Messages   []string
+	Body       []byte
+}
+
+func newAPIError(method, route string, resp *http.Response, body []byte) *APIError {
+	apiErr := APIError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Method:     method,
+		Route:      route,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+		Body:       body,
+	}
+
+	// The body is only informational, so a response that isn't JSON
+	// shouldn't hide the status code from the caller.
+	var messages Messages
+	if json.Unmarshal(body, &messages) == nil {
+		apiErr.Messages = messages.Messages
+	}
+
+	return &apiErr
+}
+
+func (e *APIError) Error() string {
+	msg := e.Method + " " + e.Route + ": " + e.Status
+	if e.RequestID != "" {
+		msg += " (request " + e.RequestID + ")"
+	}
+	if len(e.Messages) > 0 {
+		msg += ": " + strings.Join(e.Messages, "; ")
+	}
+
+	return msg
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ServerError:
+		return e.StatusCode >= 500
+	}
+
+	return false
+}
@@ -0,0 +1,180 @@
+package ios
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestParseStrings(t *testing.T) {
+	f, err := os.Open("../test_data/Localizable.strings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	s, err := ParseStrings(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Entry{
+		{"main.title", "Inbox", "Title of the main screen"},
+		{"unread", "%d unread messages from %@", "Number of unread messages"},
+		{"quote", "Say \"hi\"\nthen leave", ""},
+		{"unquoted_key", "Plain", ""},
+	}
+	if !reflect.DeepEqual(s.Entries, expected) {
+		t.Errorf("Expected\n%q\ngot\n%q", expected, s.Entries)
+	}
+
+	units := s.Units()
+	if specifiers := units[1].Specifiers(); !reflect.DeepEqual(specifiers, []string{"%d", "%@"}) {
+		t.Errorf("Unexpected specifiers %v", specifiers)
+	}
+
+	var buf bytes.Buffer
+	if err := s.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	written, err := ParseStrings(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(written, s) {
+		t.Errorf("Expected round trip to keep\n%q\ngot\n%q", s, written)
+	}
+}
+
+func TestWriteStringsComments(t *testing.T) {
+	s := &Strings{Entries: []Entry{
+		{"glob", "All files", "Matches */*.txt"},
+		{"lines", "Two", "First line\nsecond */ line"},
+		{"plain", "Plain", "A /* nested */ comment"},
+	}}
+
+	var buf bytes.Buffer
+	if err := s.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	written, err := ParseStrings(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(written, s) {
+		t.Errorf("Expected round trip to keep\n%q\ngot\n%q", s, written)
+	}
+}
+
+func TestParseStringsUTF16(t *testing.T) {
+	text := "/* Greeting */\n\"hello\" = \"Grüß dich\";\n"
+	data := []byte{0xFF, 0xFE}
+	for _, unit := range utf16.Encode([]rune(text)) {
+		data = append(data, byte(unit), byte(unit>>8))
+	}
+
+	s, err := ParseStrings(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry := s.Find("hello"); entry == nil || entry.Value != "Grüß dich" || entry.Comment != "Greeting" {
+		t.Errorf("Unexpected entries %q", s.Entries)
+	}
+}
+
+func TestParseStringsErrors(t *testing.T) {
+	tests := []string{
+		`"a" = "b"`,
+		`"a" "b";`,
+		`"a" = "b`,
+		`/* open`,
+	}
+
+	for _, test := range tests {
+		if _, err := ParseStrings(strings.NewReader(test)); err == nil {
+			t.Errorf("Expected %q to fail", test)
+		}
+	}
+}
+
+func TestStringsDict(t *testing.T) {
+	original, err := os.ReadFile("../test_data/Localizable.stringsdict")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := ParseStringsDict(bytes.NewReader(original))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry := d.Find("%d files in %d folders")
+	if entry == nil || entry.Format != "%#@files@ in %#@folders@" || len(entry.Variables) != 2 {
+		t.Fatalf("Unexpected entry %+v", entry)
+	}
+
+	files := entry.Variables[0]
+	if files.Name != "files" || files.ValueType != "d" || files.Forms["other"] != "%d files" {
+		t.Errorf("Unexpected variable %+v", files)
+	}
+
+	units := d.Units()
+	if len(units) != 5 || units[2].Key != "%d files in %d folders/files[other]" {
+		t.Errorf("Unexpected units %q", units)
+	}
+	if specifiers := units[0].Specifiers(); !reflect.DeepEqual(specifiers, []string{"%#@files@", "%#@folders@"}) {
+		t.Errorf("Unexpected specifiers %v", specifiers)
+	}
+
+	var buf bytes.Buffer
+	if err := d.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != string(original) {
+		t.Errorf("Expected\n%s\ngot\n%s", original, buf.String())
+	}
+}
+
+func TestDir(t *testing.T) {
+	tests := []struct {
+		code, dir, regional string
+	}{
+		{"es-ES", "es.lproj", "es-ES.lproj"},
+		{"pt_BR", "pt.lproj", "pt-BR.lproj"},
+		{"zh-CN", "zh-Hans.lproj", "zh-Hans-CN.lproj"},
+		{"zh-TW", "zh-Hant.lproj", "zh-Hant-TW.lproj"},
+		{"de", "de.lproj", "de.lproj"},
+	}
+
+	for _, test := range tests {
+		if dir := Dir(test.code); dir != test.dir {
+			t.Errorf("%s: expected %s, got %s", test.code, test.dir, dir)
+		}
+		if dir := RegionalDir(test.code); dir != test.regional {
+			t.Errorf("%s: expected %s, got %s", test.code, test.regional, dir)
+		}
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	root := t.TempDir()
+	s := Strings{Entries: []Entry{{Key: "hello", Value: "Hola"}}}
+
+	if err := s.WriteFile(root, "es-ES", "Localizable.strings"); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(root, "es.lproj", "Localizable.strings"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "\"hello\" = \"Hola\";\n" {
+		t.Errorf("Unexpected content %q", content)
+	}
+}
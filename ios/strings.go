@@ -0,0 +1,403 @@
+// Package ios reads and writes the string resources of iOS and macOS
+// apps, Localizable.strings and Localizable.stringsdict, and maps
+// Lingotek locales to .lproj directories.
+package ios
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	lingotek "github.com/CuriousLLC/Lingotek"
+)
+
+// Format names of Lingotek for .strings and .stringsdict files.
+const (
+	StringsFormat     = "IOS_STRINGS"
+	StringsDictFormat = "IOS_STRINGSDICT"
+)
+
+var NotStringsDict = errors.New("Not a stringsdict file")
+
+// Strings is a parsed .strings file.
+type Strings struct {
+	Entries []Entry
+}
+
+// Entry is one "key" = "value"; pair, with the comment before it.
+type Entry struct {
+	Key     string
+	Value   string
+	Comment string
+}
+
+// Find returns the entry with the given key, or nil.
+func (s *Strings) Find(key string) *Entry {
+	for i := range s.Entries {
+		if s.Entries[i].Key == key {
+			return &s.Entries[i]
+		}
+	}
+
+	return nil
+}
+
+// ParseStrings reads a .strings file in UTF-8, or in UTF-16 with a byte
+// order mark as older Xcode versions wrote them.
+func ParseStrings(r io.Reader) (*Strings, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := stringsParser{input: []rune(decodeText(data)), line: 1}
+	return p.parse()
+}
+
+// decodeText decodes UTF-16 text with a byte order mark, and strips the
+// mark of UTF-8 text.
+func decodeText(data []byte) string {
+	if len(data) < 2 || !(data[0] == 0xFF && data[1] == 0xFE || data[0] == 0xFE && data[1] == 0xFF) {
+		return strings.TrimPrefix(string(data), "\uFEFF")
+	}
+
+	bigEndian := data[0] == 0xFE
+	units := make([]uint16, 0, len(data)/2)
+	for i := 2; i+1 < len(data); i += 2 {
+		if bigEndian {
+			units = append(units, uint16(data[i])<<8|uint16(data[i+1]))
+		} else {
+			units = append(units, uint16(data[i+1])<<8|uint16(data[i]))
+		}
+	}
+
+	return string(utf16.Decode(units))
+}
+
+type stringsParser struct {
+	input   []rune
+	pos     int
+	line    int
+	comment string
+	// lineComment is the line following the last // comment, which a
+	// // comment on that line continues.
+	lineComment int
+}
+
+func (p *stringsParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("strings: line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *stringsParser) peek(s string) bool {
+	return strings.HasPrefix(string(p.input[p.pos:min(p.pos+len(s), len(p.input))]), s)
+}
+
+func (p *stringsParser) advance(n int) {
+	for ; n > 0 && p.pos < len(p.input); n-- {
+		if p.input[p.pos] == '\n' {
+			p.line++
+		}
+		p.pos++
+	}
+}
+
+// skip skips whitespace and comments, remembering the last comment.
+func (p *stringsParser) skip() error {
+	for p.pos < len(p.input) {
+		switch {
+		case unicode.IsSpace(p.input[p.pos]):
+			p.advance(1)
+		case p.peek("/*"):
+			end := strings.Index(string(p.input[p.pos+2:]), "*/")
+			if end < 0 {
+				return p.errorf("unterminated comment")
+			}
+			text := string(p.input[p.pos+2:])[:end]
+			p.comment = strings.TrimSpace(text)
+			p.lineComment = 0
+			p.advance(len([]rune(text)) + 4)
+		case p.peek("//"):
+			start := p.pos + 2
+			for p.pos < len(p.input) && p.input[p.pos] != '\n' {
+				p.pos++
+			}
+			text := strings.TrimSpace(string(p.input[start:p.pos]))
+			if p.line == p.lineComment {
+				p.comment += "\n" + text
+			} else {
+				p.comment = text
+			}
+			p.lineComment = p.line + 1
+		default:
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func (p *stringsParser) parse() (*Strings, error) {
+	var s Strings
+
+	for {
+		p.comment, p.lineComment = "", 0
+		if err := p.skip(); err != nil {
+			return nil, err
+		}
+		if p.pos == len(p.input) {
+			return &s, nil
+		}
+
+		entry := Entry{Comment: p.comment}
+
+		key, err := p.token()
+		if err != nil {
+			return nil, err
+		}
+		entry.Key = key
+
+		if err := p.expect('='); err != nil {
+			return nil, err
+		}
+		if err := p.skip(); err != nil {
+			return nil, err
+		}
+
+		entry.Value, err = p.token()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expect(';'); err != nil {
+			return nil, err
+		}
+
+		s.Entries = append(s.Entries, entry)
+	}
+}
+
+func (p *stringsParser) expect(r rune) error {
+	if err := p.skip(); err != nil {
+		return err
+	}
+	if p.pos == len(p.input) || p.input[p.pos] != r {
+		return p.errorf("expected %q", r)
+	}
+
+	p.advance(1)
+	return nil
+}
+
+// token reads a quoted string, or an unquoted key made of letters,
+// digits and underscores.
+func (p *stringsParser) token() (string, error) {
+	if p.pos < len(p.input) && p.input[p.pos] != '"' {
+		start := p.pos
+		for p.pos < len(p.input) && (unicode.IsLetter(p.input[p.pos]) || unicode.IsDigit(p.input[p.pos]) || p.input[p.pos] == '_' || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		if p.pos == start {
+			return "", p.errorf("expected a string")
+		}
+		return string(p.input[start:p.pos]), nil
+	}
+
+	var b strings.Builder
+	p.advance(1)
+	for p.pos < len(p.input) {
+		r := p.input[p.pos]
+		p.advance(1)
+
+		switch r {
+		case '"':
+			return b.String(), nil
+		case '\\':
+			if p.pos == len(p.input) {
+				return "", p.errorf("unterminated string")
+			}
+
+			e := p.input[p.pos]
+			p.advance(1)
+			switch e {
+			case 'n':
+				b.WriteRune('\n')
+			case 't':
+				b.WriteRune('\t')
+			case 'r':
+				b.WriteRune('\r')
+			case 'U', 'u':
+				if p.pos+4 > len(p.input) {
+					return "", p.errorf("malformed escape")
+				}
+				var code rune
+				_, err := fmt.Sscanf(string(p.input[p.pos:p.pos+4]), "%04x", &code)
+				if err != nil {
+					return "", p.errorf("malformed escape")
+				}
+				b.WriteRune(code)
+				p.advance(4)
+			default:
+				b.WriteRune(e)
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return "", p.errorf("unterminated string")
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+
+// Write writes the file in UTF-8, one entry per paragraph.
+func (s *Strings) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	for i, entry := range s.Entries {
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+		switch {
+		case strings.Contains(entry.Comment, "*/"):
+			// Block comments can't contain */, and there is no escape
+			for _, line := range strings.Split(entry.Comment, "\n") {
+				bw.WriteString("// " + line + "\n")
+			}
+		case entry.Comment != "":
+			bw.WriteString("/* " + entry.Comment + " */\n")
+		}
+		bw.WriteString(`"` + escaper.Replace(entry.Key) + `" = "` + escaper.Replace(entry.Value) + "\";\n")
+	}
+
+	return bw.Flush()
+}
+
+// UploadRequest returns a request uploading the file to a project as a
+// document in the given source locale.
+func (s *Strings) UploadRequest(title, localeCode, projectId string) (lingotek.UploadRequest, error) {
+	var buf bytes.Buffer
+	err := s.Write(&buf)
+	if err != nil {
+		return lingotek.UploadRequest{}, err
+	}
+
+	return uploadRequest(title, localeCode, projectId, StringsFormat, "Localizable.strings", &buf), nil
+}
+
+func uploadRequest(title, localeCode, projectId, format, filename string, content io.Reader) lingotek.UploadRequest {
+	return lingotek.UploadRequest{
+		Title:      title,
+		LocaleCode: localeCode,
+		ProjectId:  projectId,
+		Format:     format,
+		Charset:    "UTF-8",
+		Filename:   filename,
+		Reader:     content,
+	}
+}
+
+var specifier = regexp.MustCompile(`%#@[^@]+@|%(\d+\$)?[-#+ 0']*(\d+|\*)?(\.(\d+|\*))?(hh|h|ll|l|q|z|t|j|L)?[@dDiuUxXoOfeEgGcCsSpaAF%]`)
+
+// FormatSpecifiers returns the String Format Specifiers in text, such as
+// "%@", "%1$lld" or the "%#@files@" variables of a stringsdict format,
+// in order. "%%" isn't included.
+func FormatSpecifiers(text string) []string {
+	var specifiers []string
+	for _, match := range specifier.FindAllString(text, -1) {
+		if match != "%%" {
+			specifiers = append(specifiers, match)
+		}
+	}
+
+	return specifiers
+}
+
+// Dir returns the .lproj directory of a Lingotek locale code, named after
+// the language alone, e.g. "es.lproj" for "es-ES". Chinese is named after
+// its script, "zh-Hans.lproj" or "zh-Hant.lproj", as Xcode does.
+func Dir(localeCode string) string {
+	language, script, region := splitTag(localeCode)
+	if language == "zh" {
+		return "zh-" + chineseScript(script, region) + ".lproj"
+	}
+
+	return language + ".lproj"
+}
+
+// RegionalDir returns the .lproj directory of a locale code including its
+// region, e.g. "pt-BR.lproj", for apps localized for several regions of
+// one language.
+func RegionalDir(localeCode string) string {
+	language, script, region := splitTag(localeCode)
+	if language == "zh" && script == "" {
+		script = chineseScript(script, region)
+	}
+
+	tag := language
+	for _, subtag := range []string{script, region} {
+		if subtag != "" {
+			tag += "-" + subtag
+		}
+	}
+
+	return tag + ".lproj"
+}
+
+func chineseScript(script, region string) string {
+	if script != "" {
+		return script
+	}
+
+	switch region {
+	case "TW", "HK", "MO":
+		return "Hant"
+	}
+
+	return "Hans"
+}
+
+func splitTag(localeCode string) (language, script, region string) {
+	subtags := strings.Split(lingotek.CanonicalTag(localeCode), "-")
+	language = subtags[0]
+	for _, subtag := range subtags[1:] {
+		if len(subtag) == 4 {
+			script = subtag
+		} else {
+			region = subtag
+		}
+	}
+
+	return language, script, region
+}
+
+// writeFile writes content as name in the locale's .lproj directory below
+// root.
+func writeFile(root, localeCode, name string, write func(io.Writer) error) error {
+	dir := filepath.Join(root, Dir(localeCode))
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	err = write(&buf)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0644)
+}
+
+// WriteFile writes the file as name, e.g. "Localizable.strings", in the
+// locale's .lproj directory below root.
+func (s *Strings) WriteFile(root, localeCode, name string) error {
+	return writeFile(root, localeCode, name, s.Write)
+}
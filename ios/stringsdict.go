@@ -0,0 +1,271 @@
+package ios
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+
+	lingotek "github.com/CuriousLLC/Lingotek"
+)
+
+// Quantities are the plural categories a stringsdict variable may give,
+// in the order they are written.
+var Quantities = []string{"zero", "one", "two", "few", "many", "other"}
+
+// StringsDict is a parsed .stringsdict file.
+type StringsDict struct {
+	Entries []PluralEntry
+}
+
+// PluralEntry is a localized string with plural variables, such as
+//
+//	"%d files" = "%#@files@" where files is "%d file" or "%d files"
+type PluralEntry struct {
+	Key string
+	// Format is the NSStringLocalizedFormatKey, referencing the
+	// variables as "%#@name@".
+	Format    string
+	Variables []Variable
+}
+
+// Variable is a plural rule of an entry.
+type Variable struct {
+	Name string
+	// ValueType is the format specifier of the number, e.g. "d".
+	ValueType string
+	// Forms maps quantities to their string.
+	Forms map[string]string
+}
+
+// Unit is a single translatable string. Strings are keyed as is;
+// stringsdict entries give one unit for their format, keyed by the entry,
+// and one per form keyed as "key/variable[one]".
+type Unit struct {
+	Key     string
+	Value   string
+	Comment string
+}
+
+// Specifiers returns the format specifiers of the unit.
+func (u *Unit) Specifiers() []string {
+	return FormatSpecifiers(u.Value)
+}
+
+// Units returns the entries of the file as units.
+func (s *Strings) Units() []Unit {
+	units := make([]Unit, len(s.Entries))
+	for i, entry := range s.Entries {
+		units[i] = Unit{entry.Key, entry.Value, entry.Comment}
+	}
+
+	return units
+}
+
+// Units returns the format and forms of every entry in order.
+func (d *StringsDict) Units() []Unit {
+	var units []Unit
+
+	for _, entry := range d.Entries {
+		units = append(units, Unit{Key: entry.Key, Value: entry.Format})
+		for _, variable := range entry.Variables {
+			for _, quantity := range Quantities {
+				if form, ok := variable.Forms[quantity]; ok {
+					key := entry.Key + "/" + variable.Name + "[" + quantity + "]"
+					units = append(units, Unit{Key: key, Value: form})
+				}
+			}
+		}
+	}
+
+	return units
+}
+
+// Find returns the entry with the given key, or nil.
+func (d *StringsDict) Find(key string) *PluralEntry {
+	for i := range d.Entries {
+		if d.Entries[i].Key == key {
+			return &d.Entries[i]
+		}
+	}
+
+	return nil
+}
+
+// plistValue is a <string> or a <dict>, the only plist types a
+// stringsdict uses.
+type plistValue struct {
+	str  string
+	dict []plistPair
+}
+
+type plistPair struct {
+	key   string
+	value plistValue
+}
+
+// ParseStringsDict reads a .stringsdict file.
+func ParseStringsDict(r io.Reader) (*StringsDict, error) {
+	decoder := xml.NewDecoder(r)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, NotStringsDict
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local == "plist" {
+			continue
+		}
+		if start.Name.Local != "dict" {
+			return nil, NotStringsDict
+		}
+
+		root, err := parseDict(decoder)
+		if err != nil {
+			return nil, err
+		}
+
+		return fromPlist(root), nil
+	}
+}
+
+// parseDict reads the pairs of a <dict> whose start was just read. Values
+// of other types are skipped.
+func parseDict(decoder *xml.Decoder) ([]plistPair, error) {
+	var pairs []plistPair
+	key := ""
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch token := token.(type) {
+		case xml.EndElement:
+			return pairs, nil
+		case xml.StartElement:
+			switch token.Name.Local {
+			case "key":
+				err = decoder.DecodeElement(&key, &token)
+			case "string":
+				var s string
+				err = decoder.DecodeElement(&s, &token)
+				pairs = append(pairs, plistPair{key, plistValue{str: s}})
+			case "dict":
+				var dict []plistPair
+				dict, err = parseDict(decoder)
+				pairs = append(pairs, plistPair{key, plistValue{dict: dict}})
+			default:
+				err = decoder.Skip()
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func fromPlist(root []plistPair) *StringsDict {
+	var d StringsDict
+
+	for _, pair := range root {
+		entry := PluralEntry{Key: pair.key}
+
+		for _, field := range pair.value.dict {
+			if field.key == "NSStringLocalizedFormatKey" {
+				entry.Format = field.value.str
+				continue
+			}
+			if field.value.dict == nil {
+				continue
+			}
+
+			variable := Variable{Name: field.key, Forms: make(map[string]string)}
+			for _, rule := range field.value.dict {
+				switch rule.key {
+				case "NSStringFormatSpecTypeKey":
+				case "NSStringFormatValueTypeKey":
+					variable.ValueType = rule.value.str
+				default:
+					variable.Forms[rule.key] = rule.value.str
+				}
+			}
+			entry.Variables = append(entry.Variables, variable)
+		}
+
+		d.Entries = append(d.Entries, entry)
+	}
+
+	return &d
+}
+
+// Write writes the file as a property list, indented as Xcode does.
+func (d *StringsDict) Write(w io.Writer) error {
+	var buf bytes.Buffer
+
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	buf.WriteString("<plist version=\"1.0\">\n<dict>\n")
+
+	for _, entry := range d.Entries {
+		writeKey(&buf, 1, entry.Key)
+		buf.WriteString("\t<dict>\n")
+		writeString(&buf, 2, "NSStringLocalizedFormatKey", entry.Format)
+
+		for _, variable := range entry.Variables {
+			writeKey(&buf, 2, variable.Name)
+			buf.WriteString("\t\t<dict>\n")
+			writeString(&buf, 3, "NSStringFormatSpecTypeKey", "NSStringPluralRuleType")
+			writeString(&buf, 3, "NSStringFormatValueTypeKey", variable.ValueType)
+			for _, quantity := range Quantities {
+				if form, ok := variable.Forms[quantity]; ok {
+					writeString(&buf, 3, quantity, form)
+				}
+			}
+			buf.WriteString("\t\t</dict>\n")
+		}
+
+		buf.WriteString("\t</dict>\n")
+	}
+
+	buf.WriteString("</dict>\n</plist>\n")
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func writeKey(buf *bytes.Buffer, depth int, key string) {
+	buf.WriteString(strings.Repeat("\t", depth) + "<key>")
+	xml.EscapeText(buf, []byte(key))
+	buf.WriteString("</key>\n")
+}
+
+func writeString(buf *bytes.Buffer, depth int, key, value string) {
+	writeKey(buf, depth, key)
+	buf.WriteString(strings.Repeat("\t", depth) + "<string>")
+	xml.EscapeText(buf, []byte(value))
+	buf.WriteString("</string>\n")
+}
+
+// UploadRequest returns a request uploading the file to a project as a
+// document in the given source locale.
+func (d *StringsDict) UploadRequest(title, localeCode, projectId string) (lingotek.UploadRequest, error) {
+	var buf bytes.Buffer
+	err := d.Write(&buf)
+	if err != nil {
+		return lingotek.UploadRequest{}, err
+	}
+
+	return uploadRequest(title, localeCode, projectId, StringsDictFormat, "Localizable.stringsdict", &buf), nil
+}
+
+// WriteFile writes the file as name, e.g. "Localizable.stringsdict", in
+// the locale's .lproj directory below root.
+func (d *StringsDict) WriteFile(root, localeCode, name string) error {
+	return writeFile(root, localeCode, name, d.Write)
+}
@@ -4,33 +4,88 @@ import (
 	"errors"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 )
 
 var ServerError = errors.New("Server returned an error")
 var EndOfList = errors.New("No next rel found")
 var IdRequired = errors.New("No ID given")
 
-const target = "https://sandbox-api.lingotek.com/api/"
+const (
+	SandboxURL    = "https://sandbox-api.lingotek.com/api/"
+	ProductionURL = "https://myaccount.lingotek.com/api/"
+)
 
 type Lingotek struct {
 	AccessToken string
 	client      *http.Client
+	baseURL     string
+	userAgent   string
+	retry       RetryPolicy
+	limiter     *RateLimiter
+	tokens      *reuseTokenSource
+}
+
+// Option configures a Lingotek client when passed to NewApi.
+type Option func(*Lingotek)
+
+// WithBaseURL points the client at an arbitrary API root. A trailing
+// slash is added if it is missing.
+func WithBaseURL(baseURL string) Option {
+	return func(l *Lingotek) {
+		if !strings.HasSuffix(baseURL, "/") {
+			baseURL += "/"
+		}
+		l.baseURL = baseURL
+	}
+}
+
+// WithSandbox points the client at the Lingotek sandbox. This is the default.
+func WithSandbox() Option {
+	return WithBaseURL(SandboxURL)
+}
+
+// WithProduction points the client at the Lingotek production API.
+func WithProduction() Option {
+	return WithBaseURL(ProductionURL)
 }
 
-func NewApi(accessToken string, client *http.Client) *Lingotek {
-	api := Lingotek{"bearer " + accessToken, client}
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(l *Lingotek) {
+		l.userAgent = userAgent
+	}
+}
+
+func NewApi(accessToken string, client *http.Client, options ...Option) *Lingotek {
+	api := Lingotek{
+		AccessToken: "bearer " + accessToken,
+		client:      client,
+		baseURL:     SandboxURL,
+	}
+
+	for _, option := range options {
+		option(&api)
+	}
+
 	return &api
 }
 
-func (l *Lingotek) createDummyResponse(path string, params *url.Values) *Response {
+// BaseURL returns the API root this client sends requests to.
+func (l *Lingotek) BaseURL() string {
+	return l.baseURL
+}
+
+func (l *Lingotek) createDummyResponse(path string, params *url.Values, offset, limit int) *Response {
 	initialResponse := Response{}
 
 	if params == nil {
 		params = &url.Values{}
 	}
 
-	params.Set("limit", "10")
-	params.Set("offset", "0")
+	params.Set("limit", strconv.Itoa(limit))
+	params.Set("offset", strconv.Itoa(offset))
 
 	selfLink := Link{
 		Rel:  []string{"self"},
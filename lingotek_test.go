@@ -2,16 +2,27 @@ package lingotek
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
 
+var update = flag.Bool("update", false, "rewrite the golden files in test_data/golden")
+
 // RewriteTransport is an http.RoundTripper that rewrites requests
 // using the provided URL's Scheme and Host, and its Path as a prefix.
 // The Opaque field is untouched.
@@ -122,7 +133,7 @@ func TestGetNextPage(t *testing.T) {
 	r.Links = append(r.Links, selfLink)
 	r.Links = append(r.Links, nextLink)
 
-	api.getNextPage(&r)
+	api.getNextPage(context.Background(), &r, nil)
 
 	req := <-rCh
 	offset := req.URL.Query().Get("offset")
@@ -132,7 +143,7 @@ func TestGetNextPage(t *testing.T) {
 
 	nextLink.Href = "test?limit=10&offset=30"
 	r.Links[1] = nextLink
-	api.getNextPage(&r)
+	api.getNextPage(context.Background(), &r, nil)
 	req = <-rCh
 	offset = req.URL.Query().Get("offset")
 	if offset != "30" {
@@ -379,7 +390,7 @@ func TestListDocuments(t *testing.T) {
 func TestUploadString(t *testing.T) {
 	p := func(r *http.Request) (fileName string) {
 		if r.Header.Get("Content-Type") != "application/x-www-form-urlencoded;charset=utf-8" {
-			t.Error("Expected Content-Type x-www-form-urlencoded, got %s", r.Header.Get("Content-Type"))
+			t.Errorf("Expected Content-Type x-www-form-urlencoded, got %s", r.Header.Get("Content-Type"))
 		}
 
 		err := r.ParseForm()
@@ -414,18 +425,18 @@ func TestUploadString(t *testing.T) {
 	}
 
 	if resp.Property.Title != "Status of My Test" {
-		t.Fatal("Expected \"Status of My Test\", got %s", resp.Property.Title)
+		t.Fatalf("Expected \"Status of My Test\", got %s", resp.Property.Title)
 	}
 
 	if resp.Property.Id != "59d28ae8-25bd-4f99-85fc-9fd4fbc2af87" {
-		t.Fatal("Expected \"59d28ae8-25bd-4f99-85fc-9fd4fbc2af87\", got %s", resp.Property.Id)
+		t.Fatalf("Expected \"59d28ae8-25bd-4f99-85fc-9fd4fbc2af87\", got %s", resp.Property.Id)
 	}
 }
 
 func TestAddTranslation(t *testing.T) {
 	p := func(r *http.Request) (fileName string) {
 		if r.Header.Get("Content-Type") != "application/x-www-form-urlencoded;charset=utf-8" {
-			t.Error("Expected Content-Type x-www-form-urlencoded, got %s", r.Header.Get("Content-Type"))
+			t.Errorf("Expected Content-Type x-www-form-urlencoded, got %s", r.Header.Get("Content-Type"))
 		}
 
 		err := r.ParseForm()
@@ -463,8 +474,12 @@ func TestAddTranslation(t *testing.T) {
 		t.Error(err)
 	}
 
-	if translation.Property.PercentComplete != 0 {
-		t.Errorf("Expected 0, got %d", translation.Property.PercentComplete)
+	if translation.Property.PercentComplete != 100 {
+		t.Errorf("Expected 100, got %d", translation.Property.PercentComplete)
+	}
+
+	if translation.Property.LocaleCode != "es-ES" {
+		t.Errorf("Expected es-ES, got %s", translation.Property.LocaleCode)
 	}
 
 }
@@ -546,6 +561,22 @@ func TestCheckStatus(t *testing.T) {
 	if resp.Status.Property.Count.Word.Total != 5 {
 		t.Errorf("Expected 5, got %d", resp.Status.Property.Count.Word.Total)
 	}
+
+	if resp.Locale.Property.LanguageCode != "es" {
+		t.Errorf("Expected es, got %q", resp.Locale.Property.LanguageCode)
+	}
+
+	// Lingotek answers 202 while the document is still importing
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	api = NewApi("dummyToken", server.Client(), WithBaseURL(server.URL))
+	_, err = api.CheckStatus(document)
+	if !errors.Is(err, ErrImporting) {
+		t.Errorf("Expected ErrImporting, got %v", err)
+	}
 }
 
 func TestGetTranslatedDocument(t *testing.T) {
@@ -586,3 +617,1394 @@ func TestGetTranslatedDocument(t *testing.T) {
 		t.Errorf("Expected len(buf)(%d) to equal n(%d)", len(buf.Bytes()), n)
 	}
 }
+
+func TestNewApiOptions(t *testing.T) {
+	api := NewApi("dummyToken", http.DefaultClient)
+	if api.BaseURL() != SandboxURL {
+		t.Errorf("Expected %s, got %s", SandboxURL, api.BaseURL())
+	}
+
+	api = NewApi("dummyToken", http.DefaultClient, WithProduction())
+	if api.BaseURL() != ProductionURL {
+		t.Errorf("Expected %s, got %s", ProductionURL, api.BaseURL())
+	}
+
+	rCh := make(chan *http.Request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "test_data/document.json")
+		rCh <- r
+	}))
+	defer server.Close()
+
+	api = NewApi("dummyToken", server.Client(), WithBaseURL(server.URL+"/custom"), WithUserAgent("lingotek-test/1.0"))
+	_, err := api.GetDocument("12345")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := <-rCh
+	if req.URL.Path != "/custom/document/12345" {
+		t.Errorf("Expected /custom/document/12345, got %s", req.URL.Path)
+	}
+
+	if req.Header.Get("User-Agent") != "lingotek-test/1.0" {
+		t.Errorf("Expected lingotek-test/1.0, got %s", req.Header.Get("User-Agent"))
+	}
+}
+
+func TestListDocumentsContext(t *testing.T) {
+	p := func(r *http.Request) (fileName string) {
+		offset := r.URL.Query().Get("offset")
+
+		if offset == "10" {
+			fileName = "test_data/test_documents_two.json"
+		} else {
+			fileName = "test_data/test_documents.json"
+		}
+
+		return
+	}
+
+	rCh := make(chan *http.Request, 3)
+	server, client := createTestServer(rCh, p)
+	defer server.Close()
+	defer close(rCh)
+
+	api := NewApi("dummyToken", &client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	documentChan, errs := api.ListDocumentsContext(ctx, nil)
+
+	cNum := 0
+	for _ = range documentChan {
+		cNum += 1
+
+		if cNum == 5 {
+			cancel()
+			break
+		}
+	}
+
+	// Drain anything sent before the goroutine noticed the cancellation
+	for _ = range documentChan {
+	}
+
+	if err := <-errs; err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestGetDocumentContextDeadline(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	api := NewApi("dummyToken", server.Client(), WithBaseURL(server.URL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := api.GetDocumentContext(ctx, "12345")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	statusCode := http.StatusNotFound
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "abc-123")
+		w.WriteHeader(statusCode)
+		io.WriteString(w, `{"messages": ["Document not found", "Check the ID"]}`)
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	api := NewApi("dummyToken", server.Client(), WithBaseURL(server.URL))
+
+	_, err := api.GetDocument("12345")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	if errors.Is(err, ServerError) {
+		t.Error("404 should not match ServerError")
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected *APIError, got %T", err)
+	}
+
+	if apiErr.StatusCode != 404 {
+		t.Errorf("Expected 404, got %d", apiErr.StatusCode)
+	}
+
+	if apiErr.Method != "GET" || apiErr.Route != "document/12345" {
+		t.Errorf("Expected GET document/12345, got %s %s", apiErr.Method, apiErr.Route)
+	}
+
+	if apiErr.RequestID != "abc-123" {
+		t.Errorf("Expected abc-123, got %s", apiErr.RequestID)
+	}
+
+	if len(apiErr.Messages) != 2 || apiErr.Messages[0] != "Document not found" {
+		t.Errorf("Expected server messages, got %v", apiErr.Messages)
+	}
+
+	statusCode = http.StatusInternalServerError
+	project := Project{}
+	project.Property.Id = "12345"
+	status, err := api.UploadString("title", "content", "en-US", project)
+	if !errors.Is(err, ServerError) {
+		t.Errorf("Expected ServerError, got %v", err)
+	}
+
+	if len(status.Messages.Messages) != 2 {
+		t.Errorf("Expected messages decoded into status, got %v", status.Messages.Messages)
+	}
+
+	statusCode = http.StatusTooManyRequests
+	var buf bytes.Buffer
+	document := Document{}
+	document.Property.Id = "12345"
+	_, err = api.GetTranslatedDocument(&document, "es-ES", &buf)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Expected ErrRateLimited, got %v", err)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	attempts := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts += 1
+		if attempts < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		http.ServeFile(w, r, "test_data/document.json")
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+	api := NewApi("dummyToken", server.Client(), WithBaseURL(server.URL), WithRetry(policy))
+
+	document, err := api.GetDocument("12345")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+
+	if document.Property.Title != "My Test" {
+		t.Errorf("Expected \"My Test\", got %s", document.Property.Title)
+	}
+
+	// POSTs are only replayed when the policy allows it
+	attempts = 0
+	project := Project{}
+	project.Property.Id = "12345"
+	_, err = api.UploadString("title", "content", "en-US", project)
+	if !errors.Is(err, ServerError) {
+		t.Errorf("Expected ServerError, got %v", err)
+	}
+
+	if attempts != 1 {
+		t.Errorf("Expected 1 attempt, got %d", attempts)
+	}
+
+	attempts = 0
+	policy.RetryPOST = true
+	api = NewApi("dummyToken", server.Client(), WithBaseURL(server.URL), WithRetry(policy))
+	_, err = api.UploadString("title", "content", "en-US", project)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+
+	for attempt := 1; attempt <= 4; attempt++ {
+		d := policy.delay(attempt, nil)
+		if d > policy.MaxDelay {
+			t.Errorf("Attempt %d: delay %s exceeds MaxDelay", attempt, d)
+		}
+	}
+
+	d := policy.delay(1, nil)
+	if d < 50*time.Millisecond || d > 100*time.Millisecond {
+		t.Errorf("Expected delay between 50ms and 100ms, got %s", d)
+	}
+
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("Retry-After", "7")
+	if d := policy.delay(1, resp); d != 7*time.Second {
+		t.Errorf("Expected 7s, got %s", d)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(100, 2)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Two requests come out of the burst, the other two wait 10ms each
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("Expected at least 15ms of waiting, got %s", elapsed)
+	}
+
+	stats := limiter.Stats()
+	if stats.Requests != 4 || stats.Delayed != 2 {
+		t.Errorf("Expected 4 requests with 2 delayed, got %+v", stats)
+	}
+
+	if limiter.Delay() <= 0 {
+		t.Error("Expected an empty bucket to report a delay")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	limiter = NewRateLimiter(0.001, 1)
+	limiter.Wait(context.Background())
+	if err := limiter.Wait(ctx); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestRateLimitedClient(t *testing.T) {
+	f := func(r *http.Request) string {
+		return "test_data/document.json"
+	}
+	rCh := make(chan *http.Request, 3)
+	server, client := createTestServer(rCh, f)
+	defer server.Close()
+	defer close(rCh)
+
+	api := NewApi("dummyToken", &client, WithRateLimit(1000, 1))
+	for i := 0; i < 3; i++ {
+		if _, err := api.GetDocument("12345"); err != nil {
+			t.Fatal(err)
+		}
+		<-rCh
+	}
+
+	if api.RateLimiter().Stats().Requests != 3 {
+		t.Errorf("Expected 3 requests through the limiter, got %d", api.RateLimiter().Stats().Requests)
+	}
+}
+
+func TestTokenSourceRefresh(t *testing.T) {
+	issued := 0
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("grant_type") != "client_credentials" {
+			t.Errorf("Expected client_credentials, got %s", r.PostForm.Get("grant_type"))
+		}
+
+		issued += 1
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"access_token": "token`+strconv.Itoa(issued)+`", "token_type": "bearer", "expires_in": 3600}`)
+	}))
+	defer tokenServer.Close()
+
+	// The first token is revoked server side, so the client has to refresh
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "bearer token2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		http.ServeFile(w, r, "test_data/document.json")
+	}))
+	defer apiServer.Close()
+
+	source := ClientCredentialsTokenSource(tokenServer.Client(), tokenServer.URL, "id", "secret")
+	api := NewApi("", apiServer.Client(), WithBaseURL(apiServer.URL), WithTokenSource(source))
+
+	for i := 0; i < 3; i++ {
+		_, err := api.GetDocument("12345")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if issued != 2 {
+		t.Errorf("Expected 2 tokens to be issued, got %d", issued)
+	}
+}
+
+func TestTokenSourceContext(t *testing.T) {
+	release := make(chan struct{})
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer tokenServer.Close()
+	defer close(release)
+
+	// A nil client falls back to http.DefaultClient
+	source := ClientCredentialsTokenSource(nil, tokenServer.URL, "id", "secret")
+	api := NewApi("", http.DefaultClient, WithBaseURL(tokenServer.URL), WithTokenSource(source))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := api.GetDocumentContext(ctx, "12345")
+			done <- err
+		}()
+	}
+
+	// Both the request fetching the token and the one waiting for it
+	// give up with the context
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-done:
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Expected the deadline to be exceeded, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected the hung token request to be cancelled")
+		}
+	}
+}
+
+func TestTokenValid(t *testing.T) {
+	token := &Token{AccessToken: "abc"}
+	if !token.Valid() {
+		t.Error("Expected a token without expiry to be valid")
+	}
+
+	token.Expiry = time.Now().Add(time.Second)
+	if token.Valid() {
+		t.Error("Expected a token about to expire to be invalid")
+	}
+
+	var nilToken *Token
+	if nilToken.Valid() {
+		t.Error("Expected a nil token to be invalid")
+	}
+}
+
+func TestPager(t *testing.T) {
+	p := func(r *http.Request) (fileName string) {
+		offset := r.URL.Query().Get("offset")
+
+		if offset == "10" {
+			fileName = "test_data/test_communities_justone.json"
+		} else {
+			fileName = "test_data/test_communitys.json"
+		}
+
+		return
+	}
+
+	rCh := make(chan *http.Request, 10)
+	server, client := createTestServer(rCh, p)
+	defer server.Close()
+	defer close(rCh)
+
+	api := NewApi("dummyToken", &client)
+	ctx := context.Background()
+
+	communities, err := api.CommunitiesPager(nil).Collect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(communities) != 11 {
+		t.Errorf("Expected 11 communities, got %d", len(communities))
+	}
+
+	pager := api.CommunitiesPager(nil)
+	for i := 0; i < 7; i++ {
+		if _, err := pager.Next(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if pager.Cursor() != 7 {
+		t.Errorf("Expected cursor 7, got %d", pager.Cursor())
+	}
+
+	// Resuming from a cursor starts a fresh request at that offset
+	resumed := api.CommunitiesPager(nil)
+	resumed.Seek(10)
+	community, err := resumed.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if community.Property.Title != communities[10].Property.Title {
+		t.Errorf("Expected %s, got %s", communities[10].Property.Title, community.Property.Title)
+	}
+
+	if _, err := resumed.Next(ctx); err != EndOfList {
+		t.Errorf("Expected EndOfList, got %v", err)
+	}
+
+	pager = api.CommunitiesPager(nil)
+	pager.SetPageSize(50)
+	cNum := 0
+	for _, err := range pager.All(ctx) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cNum += 1
+		if cNum == 3 {
+			break
+		}
+	}
+
+	// Two requests for Collect, one each for the first pager and the
+	// resumed one, and finally the request with the new page size
+	for i := 0; i < 4; i++ {
+		<-rCh
+	}
+	if limit := (<-rCh).URL.Query().Get("limit"); limit != "50" {
+		t.Errorf("Expected limit 50, got %s", limit)
+	}
+}
+
+func TestPagerSeekMaxItems(t *testing.T) {
+	p := func(r *http.Request) string {
+		if r.URL.Query().Get("offset") == "10" {
+			return "test_data/test_communities_justone.json"
+		}
+
+		return "test_data/test_communitys.json"
+	}
+
+	rCh := make(chan *http.Request, 10)
+	server, client := createTestServer(rCh, p)
+	defer server.Close()
+	defer close(rCh)
+
+	api := NewApi("dummyToken", &client)
+	ctx := context.Background()
+
+	pager := api.CommunitiesPager(&ListOptions{MaxItems: 3})
+	for i := 0; i < 3; i++ {
+		if _, err := pager.Next(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := pager.Next(ctx); err != EndOfList {
+		t.Fatalf("Expected EndOfList after MaxItems, got %v", err)
+	}
+
+	// The limit starts over after a Seek
+	pager.Seek(10)
+	if _, err := pager.Next(ctx); err != nil {
+		t.Fatalf("Expected an entity after seeking, got %v", err)
+	}
+	if pager.Cursor() != 11 {
+		t.Errorf("Expected cursor 11, got %d", pager.Cursor())
+	}
+}
+
+func TestListOptions(t *testing.T) {
+	p := func(r *http.Request) (fileName string) {
+		offset := r.URL.Query().Get("offset")
+
+		if offset == "10" {
+			fileName = "test_data/test_projects_three.json"
+		} else {
+			fileName = "test_data/test_projects.json"
+		}
+
+		return
+	}
+
+	rCh := make(chan *http.Request, 3)
+	server, client := createTestServer(rCh, p)
+	defer server.Close()
+	defer close(rCh)
+
+	api := NewApi("dummyToken", &client)
+	community := Community{}
+	community.Property.Id = "dummycommunity"
+
+	opts := ListOptions{PageSize: 25, Offset: 10}
+	projectChan, errs := api.ListProjectsContext(context.Background(), &community, &opts)
+
+	cNum := 0
+	for _ = range projectChan {
+		cNum += 1
+	}
+
+	if err := <-errs; err != nil {
+		t.Error(err)
+	}
+
+	if cNum != 3 {
+		t.Errorf("Expected 3 projects, got %d", cNum)
+	}
+
+	req := <-rCh
+	if req.URL.Query().Get("limit") != "25" || req.URL.Query().Get("offset") != "10" {
+		t.Errorf("Expected limit 25 and offset 10, got %s", req.URL.RawQuery)
+	}
+
+	opts = ListOptions{MaxItems: 4}
+	projects, err := api.ProjectsPager(&community, &opts).Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(projects) != 4 {
+		t.Errorf("Expected 4 projects, got %d", len(projects))
+	}
+
+	req = <-rCh
+	if req.URL.Query().Get("limit") != "4" {
+		t.Errorf("Expected limit 4, got %s", req.URL.Query().Get("limit"))
+	}
+
+	projects, err = api.GetProjectsPage("dummycommunity", 10, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(projects) != 3 {
+		t.Errorf("Expected 3 projects, got %d", len(projects))
+	}
+	<-rCh
+}
+
+func TestDocumentListOptions(t *testing.T) {
+	p := func(r *http.Request) (fileName string) {
+		offset := r.URL.Query().Get("offset")
+
+		if offset == "10" {
+			fileName = "test_data/test_documents_two.json"
+		} else {
+			fileName = "test_data/test_documents.json"
+		}
+
+		return
+	}
+
+	rCh := make(chan *http.Request, 3)
+	server, client := createTestServer(rCh, p)
+	defer server.Close()
+	defer close(rCh)
+
+	api := NewApi("dummyToken", &client)
+
+	opts := DocumentListOptions{
+		ProjectId:     "72106daf-69f9-4366-8ad8-2c52af9ca3ee",
+		Title:         "My New",
+		UploadedAfter: time.Unix(1401064842, 0),
+		Sort:          SortByUploadDate,
+		Descending:    true,
+	}
+
+	documents, err := api.DocumentsPager(&opts).Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(documents) != 12 {
+		t.Errorf("Expected 12 documents, got %d", len(documents))
+	}
+
+	// The fixtures' self links don't echo the filters, so the second
+	// request proves they are carried across pages regardless
+	for i := 0; i < 2; i++ {
+		query := (<-rCh).URL.Query()
+
+		if query.Get("project_id") != opts.ProjectId {
+			t.Errorf("Expected project_id %s, got %s", opts.ProjectId, query.Get("project_id"))
+		}
+
+		if query.Get("title") != "My New" {
+			t.Errorf("Expected title \"My New\", got %s", query.Get("title"))
+		}
+
+		if query.Get("upload_date_start") != "1401064842000" {
+			t.Errorf("Expected upload_date_start 1401064842000, got %s", query.Get("upload_date_start"))
+		}
+
+		if query.Get("sort") != "upload_date" || query.Get("order") != "desc" {
+			t.Errorf("Expected sort upload_date desc, got %s %s", query.Get("sort"), query.Get("order"))
+		}
+	}
+}
+
+func TestUploadDocument(t *testing.T) {
+	p := func(r *http.Request) (fileName string) {
+		err := r.ParseMultipartForm(1 << 20)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if r.FormValue("project_id") != "12345" {
+			t.Errorf("Expected 12345, got %s", r.FormValue("project_id"))
+		}
+
+		if r.FormValue("format") != "PLAINTEXT" {
+			t.Errorf("Expected PLAINTEXT, got %s", r.FormValue("format"))
+		}
+
+		if r.FormValue("callback_url") != "https://example.com/callback" {
+			t.Errorf("Expected https://example.com/callback, got %s", r.FormValue("callback_url"))
+		}
+
+		if _, ok := r.MultipartForm.Value["external_url"]; ok {
+			t.Error("Expected empty fields to be left out")
+		}
+
+		file, header, err := r.FormFile("content")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+
+		if header.Filename != "big_document.bin" {
+			t.Errorf("Expected big_document.bin, got %s", header.Filename)
+		}
+
+		n, _ := io.Copy(io.Discard, file)
+		if n != 102400 {
+			t.Errorf("Expected 102400 bytes, got %d", n)
+		}
+
+		return "test_data/status.json"
+	}
+
+	rCh := make(chan *http.Request, 1)
+	server, client := createTestServer(rCh, p)
+	defer server.Close()
+	defer close(rCh)
+
+	content, err := os.Open("test_data/big_document.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer content.Close()
+
+	api := NewApi("dummyToken", &client)
+	status, err := api.UploadDocument(UploadRequest{
+		Title:       "Big Document",
+		LocaleCode:  "en-US",
+		ProjectId:   "12345",
+		Format:      "PLAINTEXT",
+		CallbackUrl: "https://example.com/callback",
+		Filename:    "big_document.bin",
+		Reader:      content,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status.Property.Id != "59d28ae8-25bd-4f99-85fc-9fd4fbc2af87" {
+		t.Errorf("Expected \"59d28ae8-25bd-4f99-85fc-9fd4fbc2af87\", got %s", status.Property.Id)
+	}
+
+	_, err = api.UploadDocument(UploadRequest{ProjectId: "12345"})
+	if err != ContentRequired {
+		t.Errorf("Expected ContentRequired, got %v", err)
+	}
+}
+
+func TestUpdateDocument(t *testing.T) {
+	p := func(r *http.Request) (fileName string) {
+		if r.Method != "PATCH" {
+			t.Errorf("Expected PATCH, got %s", r.Method)
+		}
+
+		if r.URL.Path != "/api/document/12345" {
+			t.Errorf("Expected /api/document/12345, got %s", r.URL.Path)
+		}
+
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			r.ParseMultipartForm(1 << 20)
+			file, _, err := r.FormFile("content")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			content, _ := io.ReadAll(file)
+			if string(content) != "Let's go to the hat store" {
+				t.Errorf("Expected new content, got %s", content)
+			}
+		} else {
+			r.ParseForm()
+			if _, ok := r.PostForm["content"]; ok {
+				t.Error("Expected no content field")
+			}
+		}
+
+		if r.FormValue("title") != "Renamed" {
+			t.Errorf("Expected Renamed, got %s", r.FormValue("title"))
+		}
+
+		return "test_data/status.json"
+	}
+
+	rCh := make(chan *http.Request, 2)
+	server, client := createTestServer(rCh, p)
+	defer server.Close()
+	defer close(rCh)
+
+	api := NewApi("dummyToken", &client)
+	document := Document{}
+	document.Property.Id = "12345"
+	opts := UpdateOptions{Title: "Renamed"}
+
+	_, err := api.UpdateDocument(&document, strings.NewReader("Let's go to the hat store"), &opts)
+	if err != nil {
+		t.Error(err)
+	}
+
+	status, err := api.UpdateDocument(&document, nil, &opts)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if status.Property.Title != "Status of My Test" {
+		t.Errorf("Expected \"Status of My Test\", got %s", status.Property.Title)
+	}
+
+	if _, err := api.UpdateDocument(&document, nil, nil); err != ContentRequired {
+		t.Errorf("Expected ContentRequired, got %v", err)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	var mu sync.Mutex
+	deleted := map[string]bool{}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			if r.URL.Query().Get("project_id") != "12345" {
+				t.Errorf("Expected project_id 12345, got %s", r.URL.Query().Get("project_id"))
+			}
+
+			if r.URL.Query().Get("offset") == "10" {
+				http.ServeFile(w, r, "test_data/test_documents_two.json")
+			} else {
+				http.ServeFile(w, r, "test_data/test_documents.json")
+			}
+			return
+		}
+
+		if r.Method != "DELETE" {
+			t.Errorf("Expected DELETE, got %s", r.Method)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if deleted[r.URL.Path] {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		deleted[r.URL.Path] = true
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	api := NewApi("dummyToken", server.Client(), WithBaseURL(server.URL))
+
+	document := Document{}
+	document.Property.Id = "abc"
+	if err := api.DeleteDocument(&document); err != nil {
+		t.Error(err)
+	}
+
+	if err := api.DeleteDocument(&document); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	if err := api.DeleteTranslation(&document, "es-ES"); err != nil {
+		t.Error(err)
+	}
+
+	project := Project{}
+	if err := api.DeleteProject(&project); err != IdRequired {
+		t.Errorf("Expected IdRequired, got %v", err)
+	}
+
+	project.Property.Id = "12345"
+	n, err := api.DeleteProjectDocuments(context.Background(), &project, 4)
+	if err != nil {
+		t.Error(err)
+	}
+
+	// The second page of the fixture repeats two documents from the first
+	if n != 12 {
+		t.Errorf("Expected 12 deletions, got %d", n)
+	}
+
+	if err := api.DeleteProject(&project); err != nil {
+		t.Error(err)
+	}
+
+	if !deleted["/project/12345"] {
+		t.Error("Expected the project to be deleted")
+	}
+}
+
+func TestProjectLifecycle(t *testing.T) {
+	p := func(r *http.Request) (fileName string) {
+		switch r.Method {
+		case "POST":
+			r.ParseForm()
+			if r.PostForm.Get("community_id") != "f49c4fca-ff93-4f01-a03e-aa36ddb1f2b8" {
+				t.Errorf("Expected community_id, got %s", r.PostForm.Get("community_id"))
+			}
+
+			if r.PostForm.Get("due_date") != "1435708800000" {
+				t.Errorf("Expected due_date 1435708800000, got %s", r.PostForm.Get("due_date"))
+			}
+		case "PATCH":
+			r.ParseForm()
+			if r.PostForm.Get("title") != "Renamed" {
+				t.Errorf("Expected Renamed, got %s", r.PostForm.Get("title"))
+			}
+
+			if r.PostForm.Get("callback_url") != "https://example.com/callback" {
+				t.Errorf("Expected callback_url, got %s", r.PostForm.Get("callback_url"))
+			}
+		}
+
+		return "test_data/project.json"
+	}
+
+	rCh := make(chan *http.Request, 3)
+	server, client := createTestServer(rCh, p)
+	defer server.Close()
+	defer close(rCh)
+
+	api := NewApi("dummyToken", &client)
+	dueDate := time.Unix(1435708800, 0)
+
+	project, err := api.CreateProject("f49c4fca-ff93-4f01-a03e-aa36ddb1f2b8", "jobName", "cc767be6-3183-4294-a37a-e0d33ef2c755", "https://example.com/callback", dueDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !project.Property.DueDate.Equal(dueDate) {
+		t.Errorf("Expected %s, got %s", dueDate, project.Property.DueDate)
+	}
+
+	project, err = api.GetProject("72106daf-69f9-4366-8ad8-2c52af9ca3ee")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if project.Property.WorkflowId != "cc767be6-3183-4294-a37a-e0d33ef2c755" {
+		t.Errorf("Expected workflow cc767be6-3183-4294-a37a-e0d33ef2c755, got %s", project.Property.WorkflowId)
+	}
+
+	project.Property.Title = "Renamed"
+	if _, err := api.UpdateProject(project); err != nil {
+		t.Error(err)
+	}
+
+	// A project written out and read back must come back unchanged
+	data, err := json.Marshal(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Project
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(decoded.Property, project.Property) {
+		t.Errorf("Expected %+v, got %+v", project.Property, decoded.Property)
+	}
+}
+
+func TestLingoTimeZero(t *testing.T) {
+	property := ProjectProperty{Title: "Undated"}
+
+	data, err := json.Marshal(property)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Contains(data, []byte(`"due_date":null`)) {
+		t.Errorf("Expected a null due_date, got %s", data)
+	}
+
+	var decoded ProjectProperty
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if !decoded.DueDate.IsZero() || !decoded.CreationDate.IsZero() {
+		t.Errorf("Expected zero dates, got %s and %s", decoded.DueDate, decoded.CreationDate)
+	}
+
+	// Older responses send 0 for a missing date
+	if err := json.Unmarshal([]byte(`{"due_date":0}`), &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if !decoded.DueDate.IsZero() {
+		t.Errorf("Expected a zero due date, got %s", decoded.DueDate)
+	}
+
+	if _, ok := property.values()["due_date"]; ok {
+		t.Errorf("Expected no due_date parameter, got %s", property.values().Get("due_date"))
+	}
+}
+
+func TestLocales(t *testing.T) {
+	p := func(r *http.Request) (fileName string) {
+		if strings.HasSuffix(r.URL.Path, "/locale/es-ES") {
+			return "test_data/locale.json"
+		}
+
+		return "test_data/locales.json"
+	}
+
+	rCh := make(chan *http.Request, 3)
+	server, client := createTestServer(rCh, p)
+	defer server.Close()
+	defer close(rCh)
+
+	api := NewApi("dummyToken", &client)
+
+	locale, err := api.GetLocale("es-ES")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if locale.Property.LanguageCode != "es" {
+		t.Errorf("Expected language code es, got %q", locale.Property.LanguageCode)
+	}
+
+	registry, err := api.LoadLocales(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(registry.Codes()) != 9 {
+		t.Errorf("Expected 9 locales, got %d", len(registry.Codes()))
+	}
+
+	resolutions := map[string]string{
+		"es-ES":       "es-ES",
+		"es_mx":       "es-MX",
+		"es":          "es-ES",
+		"es-AR":       "es-ES",
+		"en":          "en-US",
+		"pt":          "pt-BR",
+		"zh-Hant-TW":  "zh-TW",
+		"sr-latn-rs":  "sr-Latn-RS",
+		"sr":          "sr-Latn-RS",
+		"de_DE.UTF-8": "de-DE",
+	}
+	for tag, expected := range resolutions {
+		code, err := registry.Resolve(tag)
+		if err != nil {
+			t.Errorf("%s: %s", tag, err)
+		}
+
+		if code != expected {
+			t.Errorf("Expected %s to resolve to %s, got %s", tag, expected, code)
+		}
+	}
+
+	if _, err := registry.Resolve("fr-FR"); err != UnknownLocale {
+		t.Errorf("Expected UnknownLocale, got %v", err)
+	}
+
+	if err := registry.Validate("es"); err != UnknownLocale {
+		t.Errorf("Expected UnknownLocale for a bare language, got %v", err)
+	}
+
+	if CanonicalTag("ZH_hans_cn") != "zh-Hans-CN" {
+		t.Errorf("Expected zh-Hans-CN, got %s", CanonicalTag("ZH_hans_cn"))
+	}
+}
+
+func TestWorkflows(t *testing.T) {
+	p := func(r *http.Request) (fileName string) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/translation"):
+			r.ParseForm()
+			if r.PostForm.Get("workflow_id") != "c675bd20-0688-11e2-892e-0800200c9a66" {
+				t.Errorf("Expected workflow_id, got %s", r.PostForm.Get("workflow_id"))
+			}
+			return "test_data/document_translate_post.json"
+		case strings.HasSuffix(r.URL.Path, "/workflow"):
+			return "test_data/workflows.json"
+		}
+
+		return "test_data/workflow.json"
+	}
+
+	rCh := make(chan *http.Request, 3)
+	server, client := createTestServer(rCh, p)
+	defer server.Close()
+	defer close(rCh)
+
+	api := NewApi("dummyToken", &client)
+
+	workflows, err := api.WorkflowsPager("f49c4fca-ff93-4f01-a03e-aa36ddb1f2b8", nil).Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(workflows) != 2 {
+		t.Fatalf("Expected 2 workflows, got %d", len(workflows))
+	}
+
+	if workflows[0].Property.Title != "Machine Translation" || len(workflows[0].Phases) != 1 {
+		t.Errorf("Expected Machine Translation with one phase, got %+v", workflows[0])
+	}
+
+	workflow, err := api.GetWorkflow("cc767be6-3183-4294-a37a-e0d33ef2c755")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, phase := range workflow.Phases {
+		names = append(names, phase.Property.Name)
+	}
+
+	if strings.Join(names, ",") != "Translation,Review,Final Review" {
+		t.Errorf("Expected phases in order, got %v", names)
+	}
+
+	document := Document{}
+	document.Property.Id = "12345"
+	opts := TranslationOptions{WorkflowId: workflows[0].Property.Id}
+	if _, err := api.AddTranslationWithOptions(&document, "es-ES", &opts); err != nil {
+		t.Error(err)
+	}
+}
+
+// checkGolden compares got, encoded as indented JSON, with the named golden
+// file. Run the tests with -update to rewrite the golden files.
+func checkGolden(t *testing.T, name string, got interface{}) {
+	t.Helper()
+
+	data, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = append(data, '\n')
+
+	golden := "test_data/golden/" + name
+	if *update {
+		if err := os.WriteFile(golden, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(data, expected) {
+		t.Errorf("%s doesn't match, got:\n%s", golden, data)
+	}
+}
+
+func TestTranslationDecoding(t *testing.T) {
+	data, err := os.ReadFile("test_data/document_translate_post.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var translation Translation
+	if err := json.Unmarshal(data, &translation); err != nil {
+		t.Fatal(err)
+	}
+
+	checkGolden(t, "translation_post.json", translation)
+
+	if len(translation.Phases) != 0 {
+		t.Errorf("Expected no phases, got %d", len(translation.Phases))
+	}
+
+	if !translation.IsComplete() {
+		t.Error("Expected the translation to be complete")
+	}
+
+	data, err = os.ReadFile("test_data/document_translate_get.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var response Response
+	if err := json.Unmarshal(data, &response); err != nil {
+		t.Fatal(err)
+	}
+
+	var translations []Translation
+	if err := json.Unmarshal(response.Entities, &translations); err != nil {
+		t.Fatal(err)
+	}
+
+	checkGolden(t, "translations_get.json", translations)
+
+	phase, ok := translations[0].Phase("Machine Translate")
+	if !ok {
+		t.Fatal("Expected a Machine Translate phase")
+	}
+
+	if phase.Property.Order != 1 || !phase.IsComplete() {
+		t.Errorf("Expected the first, completed phase, got %+v", phase.Property)
+	}
+
+	if _, ok := translations[0].Phase("Review"); ok {
+		t.Error("Expected no Review phase")
+	}
+
+	if translations[0].Locale.Property.Code != "en-US" {
+		t.Errorf("Expected en-US, got %s", translations[0].Locale.Property.Code)
+	}
+}
+
+func TestWaitForDocument(t *testing.T) {
+	polls := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls += 1
+
+		switch polls {
+		case 1:
+			w.WriteHeader(http.StatusNotFound)
+		case 2:
+			w.WriteHeader(http.StatusAccepted)
+		case 3:
+			http.ServeFile(w, r, "test_data/document.json")
+		default:
+			http.ServeFile(w, r, "test_data/document_complete.json")
+		}
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	api := NewApi("dummyToken", server.Client(), WithBaseURL(server.URL))
+	document := Document{}
+	document.Property.Id = "59d28ae8-25bd-4f99-85fc-9fd4fbc2af87"
+
+	var progress []int
+	opts := WaitOptions{
+		Interval:   time.Millisecond,
+		OnProgress: func(percent int) { progress = append(progress, percent) },
+	}
+
+	result, err := api.WaitForDocument(context.Background(), &document, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if polls != 4 {
+		t.Errorf("Expected 4 polls, got %d", polls)
+	}
+
+	if result.Status.Property.Progress != 100 {
+		t.Errorf("Expected progress 100, got %d", result.Status.Property.Progress)
+	}
+
+	if len(progress) != 4 || progress[3] != 100 {
+		t.Errorf("Expected 4 progress reports ending at 100, got %v", progress)
+	}
+
+	// A document that never finishes importing times out
+	polls = 0
+	opts = WaitOptions{Interval: time.Millisecond, Timeout: 20 * time.Millisecond}
+	handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	slowServer := httptest.NewServer(handler)
+	defer slowServer.Close()
+
+	api = NewApi("dummyToken", slowServer.Client(), WithBaseURL(slowServer.URL))
+	_, err = api.WaitForDocument(context.Background(), &document, &opts)
+	if err != WaitTimeout {
+		t.Errorf("Expected WaitTimeout, got %v", err)
+	}
+}
+
+func TestWaitForTranslation(t *testing.T) {
+	f := func(r *http.Request) string {
+		return "test_data/document_translate_get.json"
+	}
+	rCh := make(chan *http.Request, 1)
+	server, client := createTestServer(rCh, f)
+	defer server.Close()
+	defer close(rCh)
+
+	api := NewApi("dummyToken", &client)
+	document := Document{}
+	document.Property.Id = "12345"
+
+	translation, err := api.WaitForTranslation(context.Background(), &document, "en_US", &WaitOptions{Interval: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if translation.Property.LocaleCode != "en-US" || !translation.IsComplete() {
+		t.Errorf("Expected a complete en-US translation, got %+v", translation.Property)
+	}
+}
+
+func TestCallbackHandler(t *testing.T) {
+	handler := NewCallbackHandler("s3cret")
+	handler.Deduplicate(time.Minute)
+
+	var uploaded []DocumentUploaded
+	var completed []TranslationCompleted
+	var phases []PhaseCompleted
+
+	handler.OnDocumentUploaded(func(ctx context.Context, e DocumentUploaded) error {
+		uploaded = append(uploaded, e)
+		return nil
+	})
+	handler.OnTranslationCompleted(func(ctx context.Context, e TranslationCompleted) error {
+		completed = append(completed, e)
+		return nil
+	})
+	handler.OnPhaseCompleted(func(ctx context.Context, e PhaseCompleted) error {
+		phases = append(phases, e)
+		if e.PhaseName == "Broken" {
+			return errors.New("handler failed")
+		}
+		return nil
+	})
+
+	deliver := func(r *http.Request) int {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	code := deliver(httptest.NewRequest("GET", "/callback?type=document_uploaded&document_id=abc&project_id=p1&secret=wrong", nil))
+	if code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a bad secret, got %d", code)
+	}
+
+	upload := "/callback?type=document_uploaded&document_id=abc&project_id=p1&secret=s3cret"
+	deliver(httptest.NewRequest("GET", upload, nil))
+	deliver(httptest.NewRequest("GET", upload, nil))
+	if len(uploaded) != 1 || uploaded[0].ProjectId != "p1" {
+		t.Errorf("Expected one deduplicated upload event, got %+v", uploaded)
+	}
+
+	// Partial progress isn't a completed translation
+	deliver(httptest.NewRequest("GET", "/callback?type=target&document_id=abc&locale_code=es-ES&progress=50&secret=s3cret", nil))
+	body := strings.NewReader("type=target&document_id=abc&locale_code=es-ES&complete=true")
+	r := httptest.NewRequest("POST", "/callback?secret=s3cret", body)
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	deliver(r)
+	if len(completed) != 1 || completed[0].LocaleCode != "es-ES" {
+		t.Errorf("Expected one es-ES completion, got %+v", completed)
+	}
+
+	r = httptest.NewRequest("POST", "/callback?secret=s3cret", strings.NewReader(`{"type": "phase", "document_id": "abc", "locale_code": "es-ES", "phase_name": "Review", "progress": 100}`))
+	r.Header.Set("Content-Type", "application/json")
+	deliver(r)
+	if len(phases) != 1 || phases[0].PhaseName != "Review" || phases[0].Progress != 100 {
+		t.Errorf("Expected a Review phase event, got %+v", phases)
+	}
+
+	// Large numbers aren't formatted in exponent notation
+	r = httptest.NewRequest("POST", "/callback?secret=s3cret", strings.NewReader(`{"type": "document_uploaded", "document_id": "def", "project_id": 1000000}`))
+	r.Header.Set("Content-Type", "application/json")
+	deliver(r)
+	if len(uploaded) != 2 || uploaded[1].ProjectId != "1000000" {
+		t.Errorf("Expected project 1000000, got %+v", uploaded)
+	}
+
+	// Failed deliveries aren't remembered, so the retry is handled again
+	broken := "/callback?type=phase&document_id=abc&phase_name=Broken&secret=s3cret"
+	if code := deliver(httptest.NewRequest("GET", broken, nil)); code != http.StatusInternalServerError {
+		t.Errorf("Expected 500, got %d", code)
+	}
+	deliver(httptest.NewRequest("GET", broken, nil))
+	if len(phases) != 3 {
+		t.Errorf("Expected the failed delivery to be retried, got %d phase events", len(phases))
+	}
+
+	if code := deliver(httptest.NewRequest("GET", "/callback?type=target&secret=s3cret", nil)); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a document_id, got %d", code)
+	}
+}
+
+func TestCallbackHandlerConcurrentDuplicates(t *testing.T) {
+	handler := NewCallbackHandler("")
+	handler.Deduplicate(time.Minute)
+
+	var calls int32
+	release := make(chan struct{})
+	handler.OnDocumentUploaded(func(ctx context.Context, e DocumentUploaded) error {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := httptest.NewRequest("GET", "/callback?type=document_uploaded&document_id=abc", nil)
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+		}()
+	}
+
+	// Let the duplicates arrive while the first delivery is still running
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("Expected one delivery to be handled, got %d", calls)
+	}
+
+	body := strings.NewReader(`{"type": "document_uploaded", "document_id": "` + strings.Repeat("a", maxCallbackBody) + `"}`)
+	r := httptest.NewRequest("POST", "/callback", body)
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for an oversized body, got %d", w.Code)
+	}
+}
@@ -0,0 +1,226 @@
+package lingotek
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+)
+
+var UnknownLocale = errors.New("Unknown locale")
+
+func (l *Lingotek) GetLocale(code string) (*Locale, error) {
+	return l.GetLocaleContext(context.Background(), code)
+}
+
+func (l *Lingotek) GetLocaleContext(ctx context.Context, code string) (*Locale, error) {
+	if code == "" {
+		return nil, IdRequired
+	}
+
+	var locale Locale
+
+	err := l.getEntity(ctx, "locale/"+code, nil, &locale)
+	return &locale, err
+}
+
+// LocalesPager returns a Pager over the locales Lingotek supports.
+func (l *Lingotek) LocalesPager(opts *ListOptions) *Pager[Locale] {
+	return newPager[Locale](l, "locale", nil, opts)
+}
+
+func (l *Lingotek) ListLocales(opts *ListOptions) (<-chan Locale, <-chan error) {
+	return l.ListLocalesContext(context.Background(), opts)
+}
+
+// ListLocalesContext streams the locales Lingotek supports. Cancelling ctx
+// stops the pagination and aborts any request in flight.
+func (l *Lingotek) ListLocalesContext(ctx context.Context, opts *ListOptions) (<-chan Locale, <-chan error) {
+	return stream(ctx, l.LocalesPager(opts), nil)
+}
+
+// LoadLocales reads the whole locale catalog into a registry.
+func (l *Lingotek) LoadLocales(ctx context.Context) (*LocaleRegistry, error) {
+	locales, err := l.LocalesPager(&ListOptions{PageSize: 100}).Collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLocaleRegistry(locales), nil
+}
+
+// defaultRegions picks the region used when only a language is given and
+// the language has no region of the same name, as "es" has "es-ES".
+var defaultRegions = map[string]string{
+	"en": "US",
+	"zh": "CN",
+	"ja": "JP",
+	"ko": "KR",
+	"sv": "SE",
+	"da": "DK",
+	"el": "GR",
+	"cs": "CZ",
+	"uk": "UA",
+	"he": "IL",
+	"hi": "IN",
+	"vi": "VN",
+	"nb": "NO",
+}
+
+// LocaleRegistry resolves BCP-47 language tags to the locale codes Lingotek
+// supports. It is read-only once created, and safe for concurrent use.
+type LocaleRegistry struct {
+	locales map[string]Locale
+	codes   []string
+}
+
+func NewLocaleRegistry(locales []Locale) *LocaleRegistry {
+	registry := LocaleRegistry{
+		locales: make(map[string]Locale, len(locales)),
+	}
+
+	for _, locale := range locales {
+		code := CanonicalTag(locale.Property.Code)
+		if _, ok := registry.locales[code]; !ok {
+			registry.codes = append(registry.codes, locale.Property.Code)
+		}
+		registry.locales[code] = locale
+	}
+
+	sort.Strings(registry.codes)
+
+	return &registry
+}
+
+// Codes returns every Lingotek locale code in the registry, sorted.
+func (r *LocaleRegistry) Codes() []string {
+	return append([]string(nil), r.codes...)
+}
+
+// Resolve returns the Lingotek locale code best matching tag. tag may be
+// any BCP-47 tag, or a code in Lingotek's or POSIX style such as "es_ES".
+// A tag with a script or region Lingotek doesn't have falls back to the
+// plain language, and a plain language to its default region.
+func (r *LocaleRegistry) Resolve(tag string) (string, error) {
+	language, script, region := splitTag(tag)
+	if language == "" {
+		return "", UnknownLocale
+	}
+
+	candidates := []string{
+		joinTag(language, script, region),
+		joinTag(language, "", region),
+	}
+
+	if defaultRegion, ok := defaultRegions[language]; ok {
+		candidates = append(candidates, joinTag(language, script, defaultRegion), joinTag(language, "", defaultRegion))
+	}
+	candidates = append(candidates, joinTag(language, script, strings.ToUpper(language)), joinTag(language, "", strings.ToUpper(language)))
+
+	for _, candidate := range candidates {
+		if locale, ok := r.locales[candidate]; ok {
+			return locale.Property.Code, nil
+		}
+	}
+
+	// Last resort, any locale of the language at all
+	for _, code := range r.codes {
+		if candidateLanguage, _, _ := splitTag(code); candidateLanguage == language {
+			return code, nil
+		}
+	}
+
+	return "", UnknownLocale
+}
+
+// Lookup returns the locale that tag resolves to.
+func (r *LocaleRegistry) Lookup(tag string) (Locale, error) {
+	code, err := r.Resolve(tag)
+	if err != nil {
+		return Locale{}, err
+	}
+
+	return r.locales[CanonicalTag(code)], nil
+}
+
+// Validate returns UnknownLocale unless code is exactly a Lingotek locale
+// code, without any fallback.
+func (r *LocaleRegistry) Validate(code string) error {
+	if _, ok := r.locales[CanonicalTag(code)]; !ok {
+		return UnknownLocale
+	}
+
+	return nil
+}
+
+// Tag returns the BCP-47 language tag of a locale, e.g. "sr-Latn-RS".
+func (l *Locale) Tag() string {
+	return CanonicalTag(l.Property.Code)
+}
+
+// CanonicalTag normalizes separators and case of a language tag, so that
+// "ES_es" becomes "es-ES" and "zh-hans-cn" becomes "zh-Hans-CN". Extension
+// and private use subtags are dropped.
+func CanonicalTag(tag string) string {
+	language, script, region := splitTag(tag)
+	return joinTag(language, script, region)
+}
+
+func splitTag(tag string) (language, script, region string) {
+	subtags := strings.FieldsFunc(tag, func(r rune) bool {
+		return r == '-' || r == '_' || r == '.' || r == '@'
+	})
+	if len(subtags) == 0 {
+		return "", "", ""
+	}
+
+	language = strings.ToLower(subtags[0])
+	for _, subtag := range subtags[1:] {
+		switch {
+		case len(subtag) == 4 && script == "" && region == "" && isAlpha(subtag):
+			script = strings.ToUpper(subtag[:1]) + strings.ToLower(subtag[1:])
+		case (len(subtag) == 2 && isAlpha(subtag)) || (len(subtag) == 3 && isDigit(subtag)):
+			if region == "" {
+				region = strings.ToUpper(subtag)
+			}
+		default:
+			// Variants, extensions and POSIX charsets aren't part of a
+			// Lingotek code
+			return
+		}
+	}
+
+	return
+}
+
+func joinTag(language, script, region string) string {
+	tag := language
+	if script != "" {
+		tag += "-" + script
+	}
+	if region != "" {
+		tag += "-" + region
+	}
+
+	return tag
+}
+
+func isAlpha(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isDigit(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,233 @@
+package lingotek
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"net/url"
+)
+
+const defaultPageSize = 10
+
+// ListOptions controls how much of a collection is listed and from where.
+// A nil *ListOptions lists everything, ten entities per request.
+type ListOptions struct {
+	// PageSize is the number of entities requested at a time.
+	PageSize int
+	// Offset is the offset of the first entity listed, for example the
+	// Cursor of a pager that was interrupted.
+	Offset int
+	// MaxItems stops the listing after this many entities, counted
+	// from the last Seek. Zero means no limit.
+	MaxItems int
+}
+
+// Pager walks a paginated collection one entity at a time, fetching pages
+// as they are needed. Its cursor is the offset of the next entity, so an
+// interrupted listing can be resumed with Seek. A Pager is not safe for
+// concurrent use.
+type Pager[T any] struct {
+	l        *Lingotek
+	route    string
+	params   url.Values
+	pageSize int
+	offset   int
+	maxItems int
+	read     int
+	response *Response
+	page     []T
+	done     bool
+}
+
+func newPager[T any](l *Lingotek, route string, params url.Values, opts *ListOptions) *Pager[T] {
+	pager := Pager[T]{
+		l:        l,
+		route:    route,
+		params:   params,
+		pageSize: defaultPageSize,
+	}
+
+	if opts != nil {
+		if opts.PageSize > 0 {
+			pager.pageSize = opts.PageSize
+		}
+		if opts.MaxItems > 0 {
+			pager.maxItems = opts.MaxItems
+			// There's no point fetching a page we'll mostly throw away
+			if pager.maxItems < pager.pageSize {
+				pager.pageSize = pager.maxItems
+			}
+		}
+		pager.offset = opts.Offset
+	}
+
+	return &pager
+}
+
+// SetPageSize changes how many entities are requested per page. Listing
+// continues from the current cursor.
+func (p *Pager[T]) SetPageSize(pageSize int) {
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+
+	p.pageSize = pageSize
+	p.reset()
+}
+
+// Cursor returns the offset of the entity the next call to Next returns.
+func (p *Pager[T]) Cursor() int {
+	return p.offset
+}
+
+// Seek moves the cursor to offset, discarding any buffered entities.
+// MaxItems counts the entities returned from then on.
+func (p *Pager[T]) Seek(offset int) {
+	p.offset = offset
+	p.read = 0
+	p.reset()
+}
+
+// reset discards the buffered page, so the next call to Next fetches a
+// page starting at the cursor.
+func (p *Pager[T]) reset() {
+	p.response = nil
+	p.page = nil
+	p.done = false
+}
+
+// Next returns the next entity, or EndOfList once the collection has been
+// exhausted or MaxItems entities have been returned. After any other error
+// Next may be called again to retry.
+func (p *Pager[T]) Next(ctx context.Context) (T, error) {
+	var entity T
+
+	if p.maxItems > 0 && p.read >= p.maxItems {
+		return entity, EndOfList
+	}
+
+	for len(p.page) == 0 {
+		if p.done {
+			return entity, EndOfList
+		}
+
+		err := p.fetch(ctx)
+		if err != nil {
+			return entity, err
+		}
+	}
+
+	entity = p.page[0]
+	p.page = p.page[1:]
+	p.offset += 1
+	p.read += 1
+
+	return entity, nil
+}
+
+// fetch loads the next page into the buffer, marking the pager done when
+// the server has nothing more to give.
+func (p *Pager[T]) fetch(ctx context.Context) error {
+	if p.response == nil {
+		params := url.Values{}
+		for key, values := range p.params {
+			params[key] = values
+		}
+		p.response = p.l.createDummyResponse(p.route, &params, p.offset, p.pageSize)
+	}
+
+	response, err := p.l.getNextPage(ctx, p.response, p.params)
+	if err == EndOfList {
+		p.done = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var page []T
+	if response.Properties.Size > 0 {
+		err = json.Unmarshal(response.Entities, &page)
+		if err != nil {
+			return err
+		}
+	}
+
+	p.response = response
+	p.page = page
+
+	// The next link can't be trusted to disappear on the last page, so we
+	// stop as soon as the total has been reached.
+	total := int(response.Properties.Total)
+	if len(page) == 0 || (total > 0 && p.offset+len(page) >= total) {
+		p.done = true
+	}
+
+	return nil
+}
+
+// All returns an iterator over the remaining entities. Iteration stops
+// after the first error, which is yielded with a zero entity.
+func (p *Pager[T]) All(ctx context.Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			entity, err := p.Next(ctx)
+			if err == EndOfList {
+				return
+			}
+
+			if !yield(entity, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Collect reads every remaining entity into a slice. On error the
+// entities read so far are returned along with it.
+func (p *Pager[T]) Collect(ctx context.Context) ([]T, error) {
+	var entities []T
+
+	for entity, err := range p.All(ctx) {
+		if err != nil {
+			return entities, err
+		}
+		entities = append(entities, entity)
+	}
+
+	return entities, nil
+}
+
+// stream feeds the pager's entities into a channel until they run out,
+// ctx is cancelled or doneChan receives a value. It backs the List*
+// methods.
+func stream[T any](ctx context.Context, pager *Pager[T], doneChan <-chan bool) (<-chan T, <-chan error) {
+	resultChan := make(chan T)
+	errChan := make(chan error, 1)
+
+	go func() {
+		defer close(resultChan)
+		defer close(errChan)
+
+		for {
+			entity, err := pager.Next(ctx)
+			if err != nil {
+				if err != EndOfList {
+					errChan <- err
+				}
+				return
+			}
+
+			select {
+			case <-doneChan:
+				return
+			case <-ctx.Done():
+				errChan <- ctx.Err()
+				return
+			case resultChan <- entity:
+			}
+		}
+	}()
+
+	return resultChan, errChan
+}
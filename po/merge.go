@@ -0,0 +1,59 @@
+package po
+
+// Merge copies the translations of a catalog downloaded from Lingotek
+// into c, which is either the locale's existing catalog or the template
+// it is created from. Entries are matched by context and msgid; entries
+// the download doesn't translate keep their current translation, and
+// entries only in the download are ignored, as adding them is msgmerge's
+// job.
+//
+// Lingotek fills the parts of an incomplete translation it hasn't got yet
+// with machine translation or source text, so unless complete is set the
+// merged entries are marked fuzzy. A complete translation clears the
+// flag.
+//
+// The Language, Plural-Forms and Content-Type headers are set for the
+// locale, and every plural entry gets as many forms as it declares.
+func (c *Catalog) Merge(translation *Catalog, localeCode string, complete bool) {
+	forms, ok := PluralForms(localeCode)
+	if !ok {
+		forms = translation.HeaderField("Plural-Forms")
+	}
+	if forms == "" {
+		forms = c.HeaderField("Plural-Forms")
+	}
+
+	c.SetHeaderField("Language", languageHeader(localeCode))
+	c.SetHeaderField("Content-Type", "text/plain; charset=UTF-8")
+	if forms != "" {
+		c.SetHeaderField("Plural-Forms", forms)
+	}
+	c.Header.SetFlag("fuzzy", false)
+
+	n := nplurals(forms)
+	for _, entry := range c.Entries {
+		if entry.Obsolete {
+			continue
+		}
+
+		translated := translation.Find(entry.Context, entry.Id)
+		if translated != nil && translated.IsTranslated() {
+			entry.Str = translated.Str
+			entry.StrPlural = append([]string(nil), translated.StrPlural...)
+			entry.SetFlag("fuzzy", !complete)
+			entry.Previous = nil
+		}
+
+		if entry.IsPlural() && n > 0 {
+			entry.StrPlural = resize(entry.StrPlural, n)
+		}
+	}
+}
+
+func resize(strs []string, n int) []string {
+	if len(strs) >= n {
+		return strs[:n]
+	}
+
+	return append(strs, make([]string, n-len(strs))...)
+}
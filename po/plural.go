@@ -0,0 +1,117 @@
+package po
+
+import (
+	"strconv"
+	"strings"
+
+	lingotek "github.com/CuriousLLC/Lingotek"
+)
+
+const (
+	pluralsOne        = "nplurals=1; plural=0;"
+	pluralsTwo        = "nplurals=2; plural=(n != 1);"
+	pluralsTwoFrench  = "nplurals=2; plural=(n > 1);"
+	pluralsSlavic     = "nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);"
+	pluralsWestSlavic = "nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;"
+)
+
+// pluralForms holds the Plural-Forms header of each language, as used by
+// gettext. Regional variants that differ from their language are listed
+// by full tag.
+var pluralForms = map[string]string{
+	"ar":    "nplurals=6; plural=(n==0 ? 0 : n==1 ? 1 : n==2 ? 2 : n%100>=3 && n%100<=10 ? 3 : n%100>=11 ? 4 : 5);",
+	"be":    pluralsSlavic,
+	"bg":    pluralsTwo,
+	"bs":    pluralsSlavic,
+	"ca":    pluralsTwo,
+	"cs":    pluralsWestSlavic,
+	"cy":    "nplurals=4; plural=(n==1) ? 0 : (n==2) ? 1 : (n != 8 && n != 11) ? 2 : 3;",
+	"da":    pluralsTwo,
+	"de":    pluralsTwo,
+	"el":    pluralsTwo,
+	"en":    pluralsTwo,
+	"eo":    pluralsTwo,
+	"es":    pluralsTwo,
+	"et":    pluralsTwo,
+	"eu":    pluralsTwo,
+	"fa":    pluralsTwoFrench,
+	"fi":    pluralsTwo,
+	"fil":   pluralsTwoFrench,
+	"fr":    pluralsTwoFrench,
+	"ga":    "nplurals=5; plural=(n==1 ? 0 : n==2 ? 1 : n<7 ? 2 : n<11 ? 3 : 4);",
+	"gl":    pluralsTwo,
+	"he":    pluralsTwo,
+	"hi":    pluralsTwo,
+	"hr":    pluralsSlavic,
+	"hu":    pluralsTwo,
+	"hy":    pluralsTwoFrench,
+	"id":    pluralsOne,
+	"is":    "nplurals=2; plural=(n%10!=1 || n%100==11);",
+	"it":    pluralsTwo,
+	"ja":    pluralsOne,
+	"ka":    pluralsOne,
+	"kk":    pluralsOne,
+	"km":    pluralsOne,
+	"ko":    pluralsOne,
+	"lt":    "nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && (n%100<10 || n%100>=20) ? 1 : 2);",
+	"lv":    "nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n != 0 ? 1 : 2);",
+	"mk":    "nplurals=2; plural=(n%10==1 && n%100!=11) ? 0 : 1;",
+	"ms":    pluralsOne,
+	"nb":    pluralsTwo,
+	"nl":    pluralsTwo,
+	"nn":    pluralsTwo,
+	"no":    pluralsTwo,
+	"pl":    "nplurals=3; plural=(n==1 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);",
+	"pt":    pluralsTwo,
+	"pt-BR": pluralsTwoFrench,
+	"ro":    "nplurals=3; plural=(n==1 ? 0 : (n==0 || (n%100 > 0 && n%100 < 20)) ? 1 : 2);",
+	"ru":    pluralsSlavic,
+	"sk":    pluralsWestSlavic,
+	"sl":    "nplurals=4; plural=(n%100==1 ? 0 : n%100==2 ? 1 : n%100==3 || n%100==4 ? 2 : 3);",
+	"sq":    pluralsTwo,
+	"sr":    pluralsSlavic,
+	"sv":    pluralsTwo,
+	"sw":    pluralsTwo,
+	"th":    pluralsOne,
+	"tr":    pluralsTwo,
+	"uk":    pluralsSlavic,
+	"ur":    pluralsTwo,
+	"vi":    pluralsOne,
+	"zh":    pluralsOne,
+}
+
+// PluralForms returns the Plural-Forms header for a locale code such as
+// "pt-BR" or "ru_RU", and false if the language isn't known.
+func PluralForms(localeCode string) (string, bool) {
+	tag := lingotek.CanonicalTag(localeCode)
+	if forms, ok := pluralForms[tag]; ok {
+		return forms, true
+	}
+
+	language, _, _ := strings.Cut(tag, "-")
+	forms, ok := pluralForms[language]
+	return forms, ok
+}
+
+// nplurals returns the number of plural forms a Plural-Forms header
+// declares, or 0.
+func nplurals(forms string) int {
+	_, rest, ok := strings.Cut(forms, "nplurals=")
+	if !ok {
+		return 0
+	}
+
+	value, _, _ := strings.Cut(rest, ";")
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0
+	}
+
+	return n
+}
+
+// languageHeader turns a locale code into the form gettext uses for the
+// Language header, e.g. "pt_BR".
+func languageHeader(localeCode string) string {
+	return strings.ReplaceAll(lingotek.CanonicalTag(localeCode), "-", "_")
+}
@@ -0,0 +1,486 @@
+// Package po reads and writes gettext PO and POT catalogs, and merges
+// translations downloaded from Lingotek into per-locale catalogs.
+package po
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	lingotek "github.com/CuriousLLC/Lingotek"
+)
+
+// Format is the Lingotek format name of PO and POT documents.
+const Format = "PO"
+
+// Catalog is a PO or POT file.
+type Catalog struct {
+	// Header is the entry with an empty msgid holding the header fields,
+	// or nil.
+	Header  *Entry
+	Entries []*Entry
+}
+
+// Entry is one message of a catalog.
+type Entry struct {
+	// Comments are translator comments, "# ".
+	Comments []string
+	// ExtractedComments are comments for translators from the source
+	// code, "#. ".
+	ExtractedComments []string
+	// References are source locations, "#: ".
+	References []string
+	// Flags such as "fuzzy" or "c-format", "#, ".
+	Flags []string
+	// Previous holds the "#| " lines describing the previous msgid of a
+	// fuzzy entry.
+	Previous []string
+
+	Context  string
+	Id       string
+	IdPlural string
+	// Str is the translation of a message without plural forms.
+	Str string
+	// StrPlural holds one translation per plural form.
+	StrPlural []string
+	// Obsolete entries are kept commented out with "#~".
+	Obsolete bool
+}
+
+// Key identifies an entry by context and msgid, joined the way gettext
+// joins them in compiled catalogs.
+func (e *Entry) Key() string {
+	if e.Context == "" {
+		return e.Id
+	}
+
+	return e.Context + "\x04" + e.Id
+}
+
+func (e *Entry) IsPlural() bool {
+	return e.IdPlural != ""
+}
+
+// IsTranslated reports whether the entry has a translation for every
+// form.
+func (e *Entry) IsTranslated() bool {
+	if !e.IsPlural() {
+		return e.Str != ""
+	}
+
+	for _, str := range e.StrPlural {
+		if str == "" {
+			return false
+		}
+	}
+
+	return len(e.StrPlural) > 0
+}
+
+func (e *Entry) HasFlag(flag string) bool {
+	for _, f := range e.Flags {
+		if f == flag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetFlag adds or removes a flag.
+func (e *Entry) SetFlag(flag string, set bool) {
+	flags := e.Flags[:0:0]
+	for _, f := range e.Flags {
+		if f != flag {
+			flags = append(flags, f)
+		}
+	}
+
+	if set {
+		// fuzzy goes first, as gettext writes it
+		if flag == "fuzzy" {
+			flags = append([]string{flag}, flags...)
+		} else {
+			flags = append(flags, flag)
+		}
+	}
+
+	e.Flags = flags
+}
+
+func (e *Entry) Fuzzy() bool {
+	return e.HasFlag("fuzzy")
+}
+
+// Find returns the entry with the given context and msgid, or nil.
+// Obsolete entries are ignored.
+func (c *Catalog) Find(context, id string) *Entry {
+	for _, entry := range c.Entries {
+		if !entry.Obsolete && entry.Context == context && entry.Id == id {
+			return entry
+		}
+	}
+
+	return nil
+}
+
+// HeaderField returns the value of a header field such as "Language".
+func (c *Catalog) HeaderField(name string) string {
+	if c.Header == nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(c.Header.Str, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(key), name) {
+			return strings.TrimSpace(value)
+		}
+	}
+
+	return ""
+}
+
+// SetHeaderField sets a header field, keeping the order of the fields.
+// New fields are added at the end.
+func (c *Catalog) SetHeaderField(name, value string) {
+	if c.Header == nil {
+		c.Header = &Entry{}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(c.Header.Str, "\n"), "\n")
+	if lines[0] == "" {
+		lines = nil
+	}
+
+	found := false
+	for i, line := range lines {
+		key, _, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(key), name) {
+			lines[i] = name + ": " + value
+			found = true
+		}
+	}
+	if !found {
+		lines = append(lines, name+": "+value)
+	}
+
+	c.Header.Str = strings.Join(lines, "\n") + "\n"
+}
+
+// Parse reads a PO or POT file.
+func Parse(r io.Reader) (*Catalog, error) {
+	p := parser{catalog: &Catalog{}}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+
+	for scanner.Scan() {
+		p.line++
+		err := p.parseLine(strings.TrimSpace(scanner.Text()))
+		if err != nil {
+			return nil, fmt.Errorf("po: line %d: %v", p.line, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	p.flush()
+	return p.catalog, nil
+}
+
+type parser struct {
+	catalog *Catalog
+	line    int
+	entry   *Entry
+	// field is the string continuation lines are appended to
+	field *string
+	// done is set once the entry has a msgstr, so that what follows
+	// starts a new entry
+	done bool
+}
+
+func (p *parser) current() *Entry {
+	if p.entry == nil {
+		p.entry = &Entry{}
+	}
+
+	return p.entry
+}
+
+func (p *parser) flush() {
+	if p.entry == nil {
+		return
+	}
+
+	entry := p.entry
+	p.entry, p.field, p.done = nil, nil, false
+
+	if entry.Id == "" && entry.Context == "" && !entry.Obsolete && p.catalog.Header == nil && len(p.catalog.Entries) == 0 {
+		p.catalog.Header = entry
+		return
+	}
+
+	p.catalog.Entries = append(p.catalog.Entries, entry)
+}
+
+func (p *parser) parseLine(line string) error {
+	if line == "" {
+		p.flush()
+		return nil
+	}
+
+	obsolete := false
+	if strings.HasPrefix(line, "#~") {
+		obsolete = true
+		line = strings.TrimSpace(line[2:])
+		if line == "" {
+			return nil
+		}
+		if strings.HasPrefix(line, "|") {
+			line = "#" + line
+		}
+	}
+
+	if strings.HasPrefix(line, "#") {
+		if p.done {
+			p.flush()
+		}
+		p.field = nil
+		p.parseComment(line)
+		return nil
+	}
+
+	if strings.HasPrefix(line, `"`) {
+		if p.field == nil {
+			return fmt.Errorf("unexpected string")
+		}
+
+		s, err := unquote(line)
+		if err != nil {
+			return err
+		}
+
+		*p.field += s
+		return nil
+	}
+
+	keyword, rest, _ := strings.Cut(line, " ")
+	value, err := unquote(strings.TrimSpace(rest))
+	if err != nil {
+		return err
+	}
+
+	if p.done && (keyword == "msgctxt" || keyword == "msgid") {
+		p.flush()
+	}
+
+	entry := p.current()
+	entry.Obsolete = entry.Obsolete || obsolete
+
+	switch {
+	case keyword == "msgctxt":
+		entry.Context = value
+		p.field = &entry.Context
+	case keyword == "msgid":
+		entry.Id = value
+		p.field = &entry.Id
+	case keyword == "msgid_plural":
+		entry.IdPlural = value
+		p.field = &entry.IdPlural
+	case keyword == "msgstr":
+		entry.Str = value
+		p.field = &entry.Str
+		p.done = true
+	case strings.HasPrefix(keyword, "msgstr[") && strings.HasSuffix(keyword, "]"):
+		n, err := strconv.Atoi(keyword[len("msgstr[") : len(keyword)-1])
+		if err != nil || n != len(entry.StrPlural) {
+			return fmt.Errorf("unexpected %s", keyword)
+		}
+
+		entry.StrPlural = append(entry.StrPlural, value)
+		p.field = &entry.StrPlural[n]
+		p.done = true
+	default:
+		return fmt.Errorf("unknown keyword %q", keyword)
+	}
+
+	return nil
+}
+
+func (p *parser) parseComment(line string) {
+	entry := p.current()
+	kind, text := "", strings.TrimPrefix(line, "#")
+	if len(text) > 0 && strings.ContainsRune(".:,|", rune(text[0])) {
+		kind, text = text[:1], text[1:]
+	}
+	text = strings.TrimPrefix(text, " ")
+
+	switch kind {
+	case ".":
+		entry.ExtractedComments = append(entry.ExtractedComments, text)
+	case ":":
+		entry.References = append(entry.References, text)
+	case ",":
+		for _, flag := range strings.Split(text, ",") {
+			if flag = strings.TrimSpace(flag); flag != "" {
+				entry.Flags = append(entry.Flags, flag)
+			}
+		}
+	case "|":
+		entry.Previous = append(entry.Previous, text)
+	default:
+		entry.Comments = append(entry.Comments, text)
+	}
+}
+
+func unquote(s string) (string, error) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", fmt.Errorf("malformed string %s", s)
+	}
+
+	var b strings.Builder
+	s = s[1 : len(s)-1]
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+
+		i++
+		if i == len(s) {
+			return "", fmt.Errorf("malformed string %s", s)
+		}
+
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'a':
+			b.WriteByte('\a')
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'v':
+			b.WriteByte('\v')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String(), nil
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+
+// Write writes the catalog in the layout gettext tools use.
+func (c *Catalog) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	entries := c.Entries
+	if c.Header != nil {
+		entries = append([]*Entry{c.Header}, entries...)
+	}
+
+	for i, entry := range entries {
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+		writeEntry(bw, entry)
+	}
+
+	return bw.Flush()
+}
+
+func writeEntry(w *bufio.Writer, e *Entry) {
+	comment := func(prefix string, lines []string) {
+		for _, line := range lines {
+			if line == "" {
+				w.WriteString(strings.TrimSpace(prefix) + "\n")
+			} else {
+				w.WriteString(prefix + line + "\n")
+			}
+		}
+	}
+
+	comment("# ", e.Comments)
+	comment("#. ", e.ExtractedComments)
+	comment("#: ", e.References)
+	if len(e.Flags) > 0 {
+		w.WriteString("#, " + strings.Join(e.Flags, ", ") + "\n")
+	}
+	comment("#| ", e.Previous)
+
+	prefix := ""
+	if e.Obsolete {
+		prefix = "#~ "
+	}
+
+	if e.Context != "" {
+		writeString(w, prefix, "msgctxt", e.Context)
+	}
+	writeString(w, prefix, "msgid", e.Id)
+
+	if !e.IsPlural() {
+		writeString(w, prefix, "msgstr", e.Str)
+		return
+	}
+
+	writeString(w, prefix, "msgid_plural", e.IdPlural)
+	for i, str := range e.StrPlural {
+		writeString(w, prefix, "msgstr["+strconv.Itoa(i)+"]", str)
+	}
+}
+
+// writeString writes a keyword and its string, splitting strings with
+// several lines after each newline.
+func writeString(w *bufio.Writer, prefix, keyword, s string) {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	if len(lines) <= 1 {
+		w.WriteString(prefix + keyword + ` "` + escaper.Replace(s) + "\"\n")
+		return
+	}
+
+	w.WriteString(prefix + keyword + " \"\"\n")
+	for _, line := range lines {
+		w.WriteString(prefix + `"` + escaper.Replace(line) + "\"\n")
+	}
+}
+
+// UploadRequest returns a request uploading the catalog to a project as
+// a document in the given source locale. Obsolete entries are left out.
+func (c *Catalog) UploadRequest(title, localeCode, projectId string) (lingotek.UploadRequest, error) {
+	upload := Catalog{Header: c.Header}
+	for _, entry := range c.Entries {
+		if !entry.Obsolete {
+			upload.Entries = append(upload.Entries, entry)
+		}
+	}
+
+	var buf bytes.Buffer
+	err := upload.Write(&buf)
+	if err != nil {
+		return lingotek.UploadRequest{}, err
+	}
+
+	return lingotek.UploadRequest{
+		Title:      title,
+		LocaleCode: localeCode,
+		ProjectId:  projectId,
+		Format:     Format,
+		Charset:    "UTF-8",
+		Reader:     &buf,
+	}, nil
+}
@@ -0,0 +1,201 @@
+package po
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func parseFile(t *testing.T, path string) *Catalog {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	catalog, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return catalog
+}
+
+func TestParse(t *testing.T) {
+	catalog := parseFile(t, "../test_data/messages.pot")
+
+	if catalog.Header == nil || !catalog.Header.Fuzzy() {
+		t.Fatalf("Expected a fuzzy header, got %+v", catalog.Header)
+	}
+	if version := catalog.HeaderField("project-id-version"); version != "example 1.0" {
+		t.Errorf("Unexpected Project-Id-Version %q", version)
+	}
+	if len(catalog.Entries) != 6 {
+		t.Fatalf("Expected 6 entries, got %d", len(catalog.Entries))
+	}
+
+	hello := catalog.Find("", "Hello, %s!")
+	if hello == nil || !reflect.DeepEqual(hello.ExtractedComments, []string{"Greeting on the home page"}) || !reflect.DeepEqual(hello.References, []string{"web/home.go:12"}) {
+		t.Errorf("Unexpected entry %+v", hello)
+	}
+
+	open := catalog.Find("menu", "Open")
+	if open == nil || !reflect.DeepEqual(open.Comments, []string{"Keep this short, it's a button"}) {
+		t.Errorf("Unexpected entry %+v", open)
+	}
+	if open == catalog.Find("status", "Open") {
+		t.Errorf("Expected entries to be told apart by context")
+	}
+
+	plural := catalog.Find("", "%d new message")
+	if plural == nil || plural.IdPlural != "%d new messages" || len(plural.StrPlural) != 2 || !plural.HasFlag("c-format") {
+		t.Errorf("Unexpected entry %+v", plural)
+	}
+
+	if catalog.Find("", "Line one\nLine \"two\"\n") == nil {
+		t.Errorf("Expected multi-line msgid to be joined")
+	}
+
+	removed := catalog.Entries[5]
+	if !removed.Obsolete || removed.Id != "Removed" || catalog.Find("", "Removed") != nil {
+		t.Errorf("Unexpected obsolete entry %+v", removed)
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	original, err := os.ReadFile("../test_data/messages.pot")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	catalog, err := Parse(bytes.NewReader(original))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := catalog.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != string(original) {
+		t.Errorf("Expected\n%s\ngot\n%s", original, buf.String())
+	}
+}
+
+func TestMerge(t *testing.T) {
+	for _, complete := range []bool{true, false} {
+		catalog := parseFile(t, "../test_data/messages.pot")
+		catalog.Merge(parseFile(t, "../test_data/messages_ru.po"), "ru-RU", complete)
+
+		if catalog.Header.Fuzzy() {
+			t.Errorf("Expected the header to no longer be fuzzy")
+		}
+		if language := catalog.HeaderField("Language"); language != "ru_RU" {
+			t.Errorf("Unexpected Language %q", language)
+		}
+		if forms := catalog.HeaderField("Plural-Forms"); !strings.HasPrefix(forms, "nplurals=3;") {
+			t.Errorf("Unexpected Plural-Forms %q", forms)
+		}
+		if charset := catalog.HeaderField("Content-Type"); charset != "text/plain; charset=UTF-8" {
+			t.Errorf("Unexpected Content-Type %q", charset)
+		}
+
+		hello := catalog.Find("", "Hello, %s!")
+		if hello.Str != "Привет, %s!" || hello.Fuzzy() == complete {
+			t.Errorf("complete=%v: unexpected entry %+v", complete, hello)
+		}
+
+		status := catalog.Find("status", "Open")
+		if status.Str != "" || status.Fuzzy() {
+			t.Errorf("Expected untranslated entry to be left alone, got %+v", status)
+		}
+
+		plural := catalog.Find("", "%d new message")
+		if len(plural.StrPlural) != 3 || plural.StrPlural[2] != "%d новых сообщений" {
+			t.Errorf("Unexpected plural entry %+v", plural)
+		}
+		if !plural.HasFlag("c-format") || plural.Fuzzy() == complete {
+			t.Errorf("complete=%v: unexpected flags %v", complete, plural.Flags)
+		}
+		if !complete && plural.Flags[0] != "fuzzy" {
+			t.Errorf("Expected fuzzy to be the first flag, got %v", plural.Flags)
+		}
+
+		// Entries missing from the download stay untranslated
+		help := catalog.Find("", "Line one\nLine \"two\"\n")
+		if help.Str != "" {
+			t.Errorf("Unexpected entry %+v", help)
+		}
+	}
+}
+
+func TestPluralForms(t *testing.T) {
+	tests := map[string]int{
+		"ja-JP": 1,
+		"en_US": 2,
+		"pt-BR": 2,
+		"pl-PL": 3,
+		"ar":    6,
+	}
+
+	for locale, expected := range tests {
+		forms, ok := PluralForms(locale)
+		if !ok || nplurals(forms) != expected {
+			t.Errorf("%s: expected %d forms, got %q", locale, expected, forms)
+		}
+	}
+
+	if forms, _ := PluralForms("pt-BR"); !strings.Contains(forms, "n > 1") {
+		t.Errorf("Expected pt-BR to use its own rule, got %q", forms)
+	}
+	if _, ok := PluralForms("xx"); ok {
+		t.Errorf("Expected unknown language to be reported")
+	}
+}
+
+func TestUploadRequest(t *testing.T) {
+	catalog := parseFile(t, "../test_data/messages.pot")
+
+	upload, err := catalog.UploadRequest("messages.pot", "en-US", "project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upload.Format != Format || upload.LocaleCode != "en-US" {
+		t.Errorf("Unexpected upload %+v", upload)
+	}
+
+	content, err := io.ReadAll(upload.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uploaded, err := Parse(bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uploaded.Entries) != 5 || uploaded.Find("menu", "Open") == nil {
+		t.Errorf("Expected every entry but the obsolete one, got %d", len(uploaded.Entries))
+	}
+	if !bytes.Contains(content, []byte("# Keep this short, it's a button\n")) {
+		t.Errorf("Expected translator comments to be kept")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"msgid \"unterminated\n",
+		"msgid \"a\"\nmsgstr[1] \"b\"\n",
+		"\"orphan\"\n",
+		"msgfoo \"a\"\n",
+	}
+
+	for _, test := range tests {
+		if _, err := Parse(strings.NewReader(test)); err == nil {
+			t.Errorf("Expected %q to fail", test)
+		}
+	}
+}
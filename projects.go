@@ -1,71 +1,201 @@
 package lingotek
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 func (l *Lingotek) GetProjects(communityId string) ([]Project, error) {
+	return l.GetProjectsContext(context.Background(), communityId)
+}
+
+func (l *Lingotek) GetProjectsContext(ctx context.Context, communityId string) ([]Project, error) {
 	v := url.Values{}
 	v.Set("community_id", communityId)
 
 	var projects []Project
 
-	err := l.getEntityCollectionPage("project", &v, &projects)
+	err := l.getEntityCollectionPage(ctx, "project", &v, &projects)
+	return projects, err
+}
+
+func (l *Lingotek) GetProject(id string) (*Project, error) {
+	return l.GetProjectContext(context.Background(), id)
+}
+
+func (l *Lingotek) GetProjectContext(ctx context.Context, id string) (*Project, error) {
+	if id == "" {
+		return nil, IdRequired
+	}
+
+	var project Project
+
+	err := l.getEntity(ctx, "project/"+id, nil, &project)
+	return &project, err
+}
+
+func (l *Lingotek) CreateProject(communityId, title, workflowId, callbackUrl string, dueDate time.Time) (*Project, error) {
+	return l.CreateProjectContext(context.Background(), communityId, title, workflowId, callbackUrl, dueDate)
+}
+
+// CreateProjectContext creates a project in a community. workflowId and
+// callbackUrl may be empty, and a zero dueDate leaves the project without one.
+func (l *Lingotek) CreateProjectContext(ctx context.Context, communityId, title, workflowId, callbackUrl string, dueDate time.Time) (*Project, error) {
+	if communityId == "" {
+		return nil, IdRequired
+	}
+
+	property := ProjectProperty{
+		Title:       title,
+		WorkflowId:  workflowId,
+		CallbackUrl: callbackUrl,
+		DueDate:     LingoTime{dueDate},
+	}
+	v := property.values()
+	v.Set("community_id", communityId)
+
+	var project Project
+
+	err := l.postEntity(ctx, "project", &v, &project)
+	return &project, err
+}
+
+func (l *Lingotek) UpdateProject(project *Project) (*Project, error) {
+	return l.UpdateProjectContext(context.Background(), project)
+}
+
+// UpdateProjectContext saves the title, workflow, callback URL and due date
+// of project, returning the project as the server now has it.
+func (l *Lingotek) UpdateProjectContext(ctx context.Context, project *Project) (*Project, error) {
+	if project.Property.Id == "" {
+		return nil, IdRequired
+	}
+
+	v := project.Property.values()
+
+	var updated Project
+
+	err := l.patchEntity(ctx, "project/"+project.Property.Id, &v, &updated)
+	return &updated, err
+}
+
+func (l *Lingotek) GetProjectsPage(communityId string, offset, limit int) ([]Project, error) {
+	return l.GetProjectsPageContext(context.Background(), communityId, offset, limit)
+}
+
+func (l *Lingotek) GetProjectsPageContext(ctx context.Context, communityId string, offset, limit int) ([]Project, error) {
+	var projects []Project
+	v := url.Values{}
+	v.Set("community_id", communityId)
+	v.Set("offset", strconv.Itoa(offset))
+	v.Set("limit", strconv.Itoa(limit))
+
+	err := l.getEntityCollectionPage(ctx, "project", &v, &projects)
 	return projects, err
 }
 
+// ProjectsPager returns a Pager over the projects in community selected
+// by opts.
+func (l *Lingotek) ProjectsPager(community *Community, opts *ListOptions) *Pager[Project] {
+	params := url.Values{}
+	params.Set("community_id", community.Property.Id)
+
+	return newPager[Project](l, "project", params, opts)
+}
+
+// ListProjects streams every project in community until doneChan receives
+// a value.
+//
+// Deprecated: use ListProjectsContext and cancel the context instead.
 func (l *Lingotek) ListProjects(community *Community, doneChan <-chan bool) (<-chan Project, <-chan error) {
-	resultChan := make(chan Project)
-	errChan := make(chan error, 1)
+	return stream(context.Background(), l.ProjectsPager(community, nil), doneChan)
+}
 
-	go func() {
-		defer close(resultChan)
-		defer close(errChan)
+// ListProjectsContext streams every project in community. Cancelling ctx
+// stops the pagination and aborts any request in flight.
+func (l *Lingotek) ListProjectsContext(ctx context.Context, community *Community, opts *ListOptions) (<-chan Project, <-chan error) {
+	return stream(ctx, l.ProjectsPager(community, opts), nil)
+}
 
-		params := url.Values{}
-		params.Set("community_id", community.Property.Id)
-		response := l.createDummyResponse("project", &params)
+// DeleteProject removes a project. A project that doesn't exist yields an
+// *APIError matching ErrNotFound.
+func (l *Lingotek) DeleteProject(project *Project) error {
+	return l.DeleteProjectContext(context.Background(), project)
+}
 
-		var projects []Project
-		var totalRead = int32(0)
+func (l *Lingotek) DeleteProjectContext(ctx context.Context, project *Project) error {
+	if project.Property.Id == "" {
+		return IdRequired
+	}
 
-		for {
-			resp, err := l.getNextPage(response)
-			if err != nil {
-				if err != EndOfList {
-					errChan <- err
-				}
-				return
-			}
+	return l.deleteEntity(ctx, "project/"+project.Property.Id, nil)
+}
 
-			response = resp
+// DeleteProjectDocuments deletes every document in project, running up to
+// concurrency deletions at once. It returns how many documents were
+// deleted, along with every error that occurred. Documents that are
+// already gone count as deleted.
+func (l *Lingotek) DeleteProjectDocuments(ctx context.Context, project *Project, concurrency int) (int, error) {
+	if project.Property.Id == "" {
+		return 0, IdRequired
+	}
 
-			if response.Properties.Size == 0 {
-				return
-			}
+	if concurrency < 1 {
+		concurrency = 1
+	}
 
-			err = json.Unmarshal(response.Entities, &projects)
-			if err != nil {
-				errChan <- err
-				return
-			}
+	// Deleting shifts the offsets of the documents after it, so the whole
+	// listing has to be read before anything is removed.
+	opts := DocumentListOptions{ProjectId: project.Property.Id}
+	documents, err := l.DocumentsPager(&opts).Collect(ctx)
+	if err != nil {
+		return 0, err
+	}
 
-			for i := 0; i < len(projects); i++ {
-				totalRead += 1
-				select {
-				case <-doneChan:
-					return
-				default:
-					resultChan <- projects[i]
-				}
-			}
+	documentChan := make(chan *Document)
+	errChan := make(chan error, len(documents))
+	var deleted int64
+	var wg sync.WaitGroup
 
-			if totalRead == response.Properties.Total {
-				return
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for document := range documentChan {
+				err := l.DeleteDocumentContext(ctx, document)
+				if err != nil && !errors.Is(err, ErrNotFound) {
+					errChan <- err
+					continue
+				}
+				atomic.AddInt64(&deleted, 1)
 			}
+		}()
+	}
 
+feed:
+	for i := range documents {
+		select {
+		case documentChan <- &documents[i]:
+		case <-ctx.Done():
+			errChan <- ctx.Err()
+			break feed
 		}
-	}()
-	return resultChan, errChan
+	}
+
+	close(documentChan)
+	wg.Wait()
+	close(errChan)
+
+	var errs []error
+	for err := range errChan {
+		errs = append(errs, err)
+	}
+
+	return int(deleted), errors.Join(errs...)
 }
@@ -0,0 +1,123 @@
+package lingotek
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket shared by every request a client makes,
+// including retries and pagination. One limiter may be shared by several
+// clients that draw from the same quota.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	stats  RateLimiterStats
+}
+
+// RateLimiterStats describes how much a RateLimiter has slowed requests.
+type RateLimiterStats struct {
+	Requests  int64
+	Delayed   int64
+	TotalWait time.Duration
+	LastWait  time.Duration
+}
+
+// NewRateLimiter allows requestsPerSecond on average, with bursts of up to
+// burst requests. The bucket starts full.
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		rate:   requestsPerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// WithRateLimit limits the client to requestsPerSecond with the given burst.
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return WithRateLimiter(NewRateLimiter(requestsPerSecond, burst))
+}
+
+// WithRateLimiter makes the client draw from an existing limiter.
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(l *Lingotek) {
+		l.limiter = limiter
+	}
+}
+
+// RateLimiter returns the client's limiter, or nil if requests aren't limited.
+func (l *Lingotek) RateLimiter() *RateLimiter {
+	return l.limiter
+}
+
+// refill adds the tokens earned since the last call. r.mu must be held.
+func (r *RateLimiter) refill(now time.Time) {
+	r.tokens += now.Sub(r.last).Seconds() * r.rate
+	if r.tokens > r.burst {
+		r.tokens = r.burst
+	}
+	r.last = now
+}
+
+// Wait blocks until a request may be made, or ctx is done.
+func (r *RateLimiter) Wait(ctx context.Context) error {
+	r.mu.Lock()
+	r.refill(time.Now())
+
+	// Take the token now, even if it isn't there yet. The negative
+	// balance makes later callers queue up behind this one.
+	r.tokens -= 1
+	wait := r.waitFor(0)
+
+	r.stats.Requests += 1
+	r.stats.LastWait = wait
+	if wait > 0 {
+		r.stats.Delayed += 1
+		r.stats.TotalWait += wait
+	}
+	r.mu.Unlock()
+
+	err := sleep(ctx, wait)
+	if err != nil {
+		r.mu.Lock()
+		r.tokens += 1
+		r.mu.Unlock()
+	}
+
+	return err
+}
+
+// waitFor returns how long until the balance reaches tokens. r.mu must
+// be held.
+func (r *RateLimiter) waitFor(tokens float64) time.Duration {
+	if r.tokens >= tokens || r.rate <= 0 {
+		return 0
+	}
+
+	return time.Duration((tokens - r.tokens) / r.rate * float64(time.Second))
+}
+
+// Delay returns how long a request made right now would have to wait.
+func (r *RateLimiter) Delay() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.refill(time.Now())
+	return r.waitFor(1)
+}
+
+// Stats returns a snapshot of the limiter's counters.
+func (r *RateLimiter) Stats() RateLimiterStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.stats
+}
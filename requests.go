@@ -2,9 +2,9 @@ package lingotek
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
-	//	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -51,10 +51,10 @@ func (r *Response) GetNext() (string, *url.Values, error) {
 }
 
 // getEntityCollection converts an array of entities into the specified type
-func (l *Lingotek) getEntityCollectionPage(route string, params *url.Values, entity interface{}) error {
+func (l *Lingotek) getEntityCollectionPage(ctx context.Context, route string, params *url.Values, entity interface{}) error {
 	var jsonResponse Response
 
-	resp, err := l.doRequest(route, "GET", params)
+	resp, err := l.doRequest(ctx, route, "GET", params)
 	if err != nil {
 		return err
 	}
@@ -72,53 +72,30 @@ func (l *Lingotek) getEntityCollectionPage(route string, params *url.Values, ent
 	return nil
 }
 
-// getEntityCollectionFull takes an initial response, and iterates through every
-// page until the amount of returned entities is 0. Each page will be sent to
-// entityChan until the done message is received, or entities run out.
-func (l *Lingotek) getEntityCollectionFull(response *Response, doneChan <-chan bool) (<-chan json.RawMessage, chan error) {
-	entityChan := make(chan json.RawMessage)
-	errChan := make(chan error)
-
-	go func() {
-		defer close(entityChan)
-		defer close(errChan)
-
-		for {
-			resp, err := l.getNextPage(response)
-			if err != nil {
-				errChan <- err
-				return
-			}
-
-			response = resp
-
-			if response.Properties.Size == 0 {
-				return
-			}
+// getNextPage takes a Response, and returns a new Response holding
+// the next set of entities. Filters are carried over from the self link,
+// but we don't rely on the server echoing every one of them back, so
+// those of filters missing from it are added.
+func (l *Lingotek) getNextPage(ctx context.Context, response *Response, filters url.Values) (*Response, error) {
+	route, params, err := response.GetNext()
+	if err != nil {
+		return nil, err
+	}
 
-			select {
-			case entityChan <- response.Entities:
-			case <-doneChan:
-				return
-			}
+	for key, values := range filters {
+		if _, ok := (*params)[key]; !ok {
+			(*params)[key] = values
 		}
+	}
 
-	}()
-
-	return entityChan, errChan
+	return l.getPage(ctx, route, params)
 }
 
-// getNextPage takes a Response, and returns a new Response holding
-// the next set of entities.
-func (l *Lingotek) getNextPage(response *Response) (*Response, error) {
+// getPage fetches a single page of a collection.
+func (l *Lingotek) getPage(ctx context.Context, route string, params *url.Values) (*Response, error) {
 	var jsonResponse Response
 
-	route, params, err := response.GetNext()
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := l.doRequest(route, "GET", params)
+	resp, err := l.doRequest(ctx, route, "GET", params)
 	if err != nil {
 		return nil, err
 	}
@@ -131,13 +108,13 @@ func (l *Lingotek) getNextPage(response *Response) (*Response, error) {
 	return &jsonResponse, nil
 }
 
-func (l *Lingotek) downloadContent(route string, params *url.Values, writer io.Writer) (int64, error) {
-	return l.streamRequest(route, "GET", params, writer)
+func (l *Lingotek) downloadContent(ctx context.Context, route string, params *url.Values, writer io.Writer) (int64, error) {
+	return l.streamRequest(ctx, route, "GET", params, writer)
 }
 
 // getEntity converts a single response entity into the specified type
-func (l *Lingotek) getEntity(route string, params *url.Values, entity interface{}) error {
-	resp, err := l.doRequest(route, "GET", params)
+func (l *Lingotek) getEntity(ctx context.Context, route string, params *url.Values, entity interface{}) error {
+	resp, err := l.doRequest(ctx, route, "GET", params)
 	if err != nil {
 		return err
 	}
@@ -150,94 +127,189 @@ func (l *Lingotek) getEntity(route string, params *url.Values, entity interface{
 	return nil
 }
 
-func (l *Lingotek) streamRequest(route, method string, params *url.Values, writer io.Writer) (int64, error) {
-	url := target + route
+// newRequest builds a request for route. Parameters of POST, PUT and
+// PATCH requests are sent as a form body, those of any other method in
+// the query string.
+func (l *Lingotek) newRequest(ctx context.Context, route, method string, params *url.Values) (*http.Request, error) {
+	var body io.Reader
+	hasBody := false
+
+	url := l.baseURL + route
 	if params != nil {
-		url += "?" + params.Encode()
+		switch method {
+		case "POST", "PUT", "PATCH":
+			body = bytes.NewBufferString(params.Encode())
+			hasBody = true
+		default:
+			url += "?" + params.Encode()
+		}
 	}
 
-	req, err := http.NewRequest(method, url, nil)
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	req.Header.Add("Authorization", l.AccessToken)
-	if method == "POST" {
-		req.Header.Add("Content-Type", "application/x-www-form-urlencoded;charset=utf-8")
+	if l.userAgent != "" {
+		req.Header.Set("User-Agent", l.userAgent)
 	}
-	resp, err := l.client.Do(req)
-	if err != nil {
-		return 0, err
+	if hasBody || method == "POST" {
+		req.Header.Add("Content-Type", "application/x-www-form-urlencoded;charset=utf-8")
 	}
 
-	defer resp.Body.Close()
+	return req, nil
+}
 
-	if resp.StatusCode >= 400 {
-		return 0, errors.New(method + url + ":" + resp.Status)
+// send performs a request built by newRequest. See sendRequest.
+func (l *Lingotek) send(ctx context.Context, route, method string, params *url.Values) (*http.Response, error) {
+	build := func() (*http.Request, error) {
+		return l.newRequest(ctx, route, method, params)
 	}
 
-	return io.Copy(writer, resp.Body)
+	return l.sendRequest(ctx, method, build, true)
 }
 
-func (l *Lingotek) doRequest(route, method string, params *url.Values) ([]byte, error) {
-	var req *http.Request
-	var err error
+// sendRequest performs a request, retrying it according to the client's
+// RetryPolicy. Every attempt waits on the client's RateLimiter first.
+// A request rejected with 401 is repeated once with a fresh token.
+// build is called for every attempt; requests whose body can only be read
+// once must pass replayable as false, which disables both kinds of retry.
+// The caller must close the returned response's body.
+func (l *Lingotek) sendRequest(ctx context.Context, method string, build func() (*http.Request, error), replayable bool) (*http.Response, error) {
+	refreshed := false
+
+	for attempt := 1; ; attempt++ {
+		if l.limiter != nil {
+			if err := l.limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
 
-	url := target + route
-	if params == nil {
-		req, err = http.NewRequest(method, url, nil)
+		req, err := build()
 		if err != nil {
 			return nil, err
 		}
-	} else {
-		if method == "GET" {
-			url += "?" + params.Encode()
-			req, err = http.NewRequest(method, url, nil)
-			if err != nil {
-				return nil, err
-			}
-		} else if method == "POST" {
-			//fmt.Println("Using POST with body", params.Encode())
-			req, err = http.NewRequest(method, url, bytes.NewBufferString(params.Encode()))
-			if err != nil {
-				return nil, err
+
+		authorization, token, err := l.authorization(ctx)
+		if err != nil {
+			if req.Body != nil {
+				req.Body.Close()
 			}
+			return nil, err
+		}
+		req.Header.Set("Authorization", authorization)
+
+		resp, err := l.client.Do(req)
+		if !replayable {
+			return resp, err
+		}
+
+		if err == nil && resp.StatusCode == http.StatusUnauthorized && token != nil && !refreshed {
+			refreshed = true
+			discardBody(resp)
+			l.tokens.invalidate(token)
+
+			// A rejected token doesn't count against the retry policy
+			attempt -= 1
+			continue
+		}
+
+		if !l.retry.shouldRetry(ctx, method, attempt, resp, err) {
+			return resp, err
+		}
+
+		delay := l.retry.delay(attempt, resp)
+		if resp != nil {
+			discardBody(resp)
+		}
+
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
 		}
 	}
+}
 
-	req.Header.Add("Authorization", l.AccessToken)
-	if method == "POST" {
-		req.Header.Add("Content-Type", "application/x-www-form-urlencoded;charset=utf-8")
+// discardBody drains and closes a response that won't be used, so the
+// connection can be reused.
+func discardBody(resp *http.Response) {
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+}
+
+func (l *Lingotek) streamRequest(ctx context.Context, route, method string, params *url.Values, writer io.Writer) (int64, error) {
+	resp, err := l.send(ctx, route, method, params)
+	if err != nil {
+		return 0, err
 	}
-	resp, err := l.client.Do(req)
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return 0, err
+		}
+
+		return 0, newAPIError(method, route, resp, body)
+	}
+
+	return io.Copy(writer, resp.Body)
+}
+
+func (l *Lingotek) doRequest(ctx context.Context, route, method string, params *url.Values) ([]byte, error) {
+	resp, err := l.send(ctx, route, method, params)
 	if err != nil {
 		return nil, err
 	}
 
+	return readResponse(method, route, resp)
+}
+
+// readResponse reads and closes the body of resp, turning error statuses
+// into an APIError. The body is returned in either case.
+func readResponse(method, route string, resp *http.Response) ([]byte, error) {
+	defer resp.Body.Close()
+
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.StatusCode >= 400 {
-		return body, errors.New(method + url + ":" + resp.Status)
+		return body, newAPIError(method, route, resp, body)
 	}
 
 	return body, nil
 }
 
-func (l *Lingotek) postEntity(route string, params *url.Values, entity interface{}) error {
-	resp, err := l.doRequest(route, "POST", params)
+func (l *Lingotek) postEntity(ctx context.Context, route string, params *url.Values, entity interface{}) error {
+	resp, err := l.doRequest(ctx, route, "POST", params)
+	return decodePosted(resp, err, entity)
+}
+
+func (l *Lingotek) patchEntity(ctx context.Context, route string, params *url.Values, entity interface{}) error {
+	resp, err := l.doRequest(ctx, route, "PATCH", params)
+	return decodePosted(resp, err, entity)
+}
+
+func (l *Lingotek) deleteEntity(ctx context.Context, route string, params *url.Values) error {
+	_, err := l.doRequest(ctx, route, "DELETE", params)
+	return err
+}
+
+// decodePosted unmarshals the response to a POST or PATCH into entity.
+func decodePosted(resp []byte, err error, entity interface{}) error {
 	if err != nil {
-		if err != ServerError {
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
 			return err
 		}
-	}
 
-	jsonErr := json.Unmarshal(resp, entity)
-	if jsonErr != nil {
-		return jsonErr
+		// Error responses usually carry the entity's messages, so decode
+		// what we can and hand back the API error either way.
+		json.Unmarshal(resp, entity)
+		return err
 	}
 
-	return err
+	return json.Unmarshal(resp, entity)
 }
@@ -0,0 +1,138 @@
+package lingotek
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried. The zero value
+// makes a single attempt, which is how clients behave unless WithRetry
+// is given.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	MaxAttempts int
+	// BaseDelay is the backoff before the second attempt. It doubles
+	// with every attempt after that.
+	BaseDelay time.Duration
+	// MaxDelay caps the computed backoff. A Retry-After header sent by
+	// the server is always honored as is.
+	MaxDelay time.Duration
+	// RetryPOST allows POST requests to be replayed. POSTs create
+	// documents and translations, so replaying one may create duplicates.
+	RetryPOST bool
+}
+
+// DefaultRetryPolicy is a reasonable policy for long running jobs.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+}
+
+// WithRetry enables retries of transient failures using policy.
+func WithRetry(policy RetryPolicy) Option {
+	return func(l *Lingotek) {
+		l.retry = policy
+	}
+}
+
+// shouldRetry decides whether the given attempt of a request may be
+// repeated. resp and err are the result of that attempt.
+func (p RetryPolicy) shouldRetry(ctx context.Context, method string, attempt int, resp *http.Response, err error) bool {
+	if attempt >= p.MaxAttempts || ctx.Err() != nil {
+		return false
+	}
+
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+	case "POST":
+		if !p.RetryPOST {
+			return false
+		}
+	default:
+		return false
+	}
+
+	if err != nil {
+		return true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// delay returns how long to wait before the next attempt. A Retry-After
+// header takes precedence over the jittered exponential backoff.
+func (p RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return wait
+		}
+	}
+
+	backoff := p.BaseDelay << uint(attempt-1)
+	if backoff <= 0 || (p.MaxDelay > 0 && backoff > p.MaxDelay) {
+		backoff = p.MaxDelay
+	}
+
+	if backoff <= 0 {
+		return 0
+	}
+
+	// Half of the backoff is fixed, the other half random, so concurrent
+	// clients don't all come back at the same moment.
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(backoff-half)+1))
+}
+
+// parseRetryAfter understands both forms of the Retry-After header,
+// delay-seconds and an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	when, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	wait := time.Until(when)
+	if wait < 0 {
+		wait = 0
+	}
+
+	return wait, true
+}
+
+// sleep waits for d, returning early with the context's error if it is
+// cancelled first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -2,6 +2,7 @@ package lingotek
 
 import (
 	"encoding/json"
+	"net/url"
 	"strconv"
 	"time"
 )
@@ -13,16 +14,37 @@ type LingoTime struct {
 }
 
 func (l *LingoTime) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" || string(data) == "0" {
+		l.Time = time.Time{}
+		return nil
+	}
+
 	i, err := strconv.ParseInt(string(data), 10, 64)
 	if err != nil {
 		return err
 	}
 
-	l.Time = time.Unix(i/1000, 0)
+	l.Time = time.UnixMilli(i)
 
 	return nil
 }
 
+// The zero time is marshalled as null, so that it survives a round trip
+// instead of coming back as the epoch.
+func (l LingoTime) MarshalJSON() ([]byte, error) {
+	if l.IsZero() {
+		return []byte("null"), nil
+	}
+
+	return []byte(l.param()), nil
+}
+
+// param returns the timestamp in the form Lingotek expects, milliseconds
+// since the epoch. Callers leave zero times out of the request.
+func (l LingoTime) param() string {
+	return strconv.FormatInt(l.UnixMilli(), 10)
+}
+
 // API Response
 // An API response will have multiple unknown entites based
 // on what method was called. These must be unmarshalled after
@@ -74,6 +96,26 @@ type ProjectProperty struct {
 	Id           string    `json:"id"`
 }
 
+// values returns the editable fields as form parameters. Empty fields
+// are left out, so they keep their value on the server.
+func (p *ProjectProperty) values() url.Values {
+	v := url.Values{}
+	if p.Title != "" {
+		v.Set("title", p.Title)
+	}
+	if p.WorkflowId != "" {
+		v.Set("workflow_id", p.WorkflowId)
+	}
+	if p.CallbackUrl != "" {
+		v.Set("callback_url", p.CallbackUrl)
+	}
+	if !p.DueDate.IsZero() {
+		v.Set("due_date", p.DueDate.param())
+	}
+
+	return v
+}
+
 type Project struct {
 	Property ProjectProperty `json:"properties"`
 	Rel      []string        `json:"rel"`
@@ -126,7 +168,7 @@ type Action struct {
 
 type LocaleProperty struct {
 	Code         string `json:"code"`
-	LanguageCode string `json:"lanuage_code"`
+	LanguageCode string `json:"language_code"`
 	CountryCode  string `json:"country_code"`
 	Title        string `json:"title"`
 	Language     string `json:"language"`
@@ -188,16 +230,22 @@ type PhaseProperty struct {
 	Order            int    `json:"order"`
 	PercentCompleted int    `json:"percent_completed"`
 	Name             string `json:"name"`
+	Status           string `json:"status"`
 }
 
 type Phase struct {
 	Property PhaseProperty `json:"properties"`
 }
 
+// IsComplete reports whether all work in the phase is done.
+func (p *Phase) IsComplete() bool {
+	return p.Property.PercentCompleted >= 100
+}
+
 type TranslationProperty struct {
 	DueDate         LingoTime `json:"due_date"`
 	PercentComplete int       `json:"percent_complete"`
-	LocaleCode      string    `json:"local_code"`
+	LocaleCode      string    `json:"locale_code"`
 }
 
 type Translation struct {
@@ -206,9 +254,9 @@ type Translation struct {
 	Locale   Locale
 }
 
-func (t *Translation) UnmarshalJson(data []byte) error {
+func (t *Translation) UnmarshalJSON(data []byte) error {
 	tObj := make(map[string]json.RawMessage)
-	entities := make([]json.RawMessage, 2)
+	var entities []json.RawMessage
 
 	err := json.Unmarshal(data, &tObj)
 	if err != nil {
@@ -220,20 +268,57 @@ func (t *Translation) UnmarshalJson(data []byte) error {
 		return err
 	}
 
+	if _, ok := tObj["entities"]; !ok {
+		return nil
+	}
+
 	err = json.Unmarshal(tObj["entities"], &entities)
 	if err != nil {
 		return err
 	}
 
-	err = json.Unmarshal(entities[0], &t.Phases)
-	if err != nil {
-		return err
+	// The phases and the locale are told apart by their class, rather
+	// than trusting their position.
+	for _, raw := range entities {
+		var entity Entity
+
+		err = json.Unmarshal(raw, &entity)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case hasClass(entity.Class, "locale"):
+			err = json.Unmarshal(raw, &t.Locale)
+		case hasClass(entity.Class, "phases"), hasClass(entity.Class, "phase"):
+			var phases []Phase
+			phases, err = unmarshalPhases(entity.Class, raw)
+			t.Phases = append(t.Phases, phases...)
+		}
+
+		if err != nil {
+			return err
+		}
 	}
 
-	err = json.Unmarshal(entities[1], &t.Locale)
-	if err != nil {
-		return err
+	sortPhases(t.Phases)
+
+	return nil
+}
+
+// Phase returns the phase called name, if the translation has one.
+func (t *Translation) Phase(name string) (*Phase, bool) {
+	for i := range t.Phases {
+		if t.Phases[i].Property.Name == name {
+			return &t.Phases[i], true
+		}
 	}
 
-	return err
+	return nil, false
+}
+
+// IsComplete reports whether the translation has made it through every
+// phase of its workflow.
+func (t *Translation) IsComplete() bool {
+	return t.Property.PercentComplete >= 100
 }
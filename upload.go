@@ -0,0 +1,143 @@
+package lingotek
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+)
+
+var ContentRequired = errors.New("No content given")
+
+// UploadRequest describes a document to upload from a stream. Title,
+// LocaleCode, ProjectId and Reader are required; everything else is
+// optional.
+type UploadRequest struct {
+	Title      string
+	LocaleCode string
+	ProjectId  string
+	// Format tells Lingotek how to parse the content, e.g. "PLAINTEXT",
+	// "XLIFF" or "JSON". Lingotek guesses from Filename when it's empty.
+	Format      string
+	Charset     string
+	ExternalUrl string
+	CallbackUrl string
+	Filename    string
+	Reader      io.Reader
+}
+
+// fields returns the form fields sent ahead of the content.
+func (u *UploadRequest) fields() url.Values {
+	v := url.Values{}
+	v.Set("title", u.Title)
+	v.Set("locale_code", u.LocaleCode)
+	v.Set("project_id", u.ProjectId)
+
+	optional := map[string]string{
+		"format":       u.Format,
+		"charset":      u.Charset,
+		"external_url": u.ExternalUrl,
+		"callback_url": u.CallbackUrl,
+	}
+	for key, value := range optional {
+		if value != "" {
+			v.Set(key, value)
+		}
+	}
+
+	return v
+}
+
+func (l *Lingotek) UploadDocument(upload UploadRequest) (*Status, error) {
+	return l.UploadDocumentContext(context.Background(), upload)
+}
+
+// UploadDocumentContext uploads the content of upload.Reader as a new
+// document. The content is streamed as multipart/form-data rather than
+// read into memory, so the request is never retried.
+func (l *Lingotek) UploadDocumentContext(ctx context.Context, upload UploadRequest) (*Status, error) {
+	if upload.Reader == nil {
+		return nil, ContentRequired
+	}
+
+	if upload.ProjectId == "" {
+		return nil, IdRequired
+	}
+
+	filename := upload.Filename
+	if filename == "" {
+		filename = upload.Title
+	}
+
+	var status Status
+	err := l.sendMultipart(ctx, "document", "POST", upload.fields(), filename, upload.Reader, &status)
+	return &status, err
+}
+
+// sendMultipart streams fields followed by content, as the "content" file
+// field, and decodes the response into entity.
+func (l *Lingotek) sendMultipart(ctx context.Context, route, method string, fields url.Values, filename string, content io.Reader, entity interface{}) error {
+	build := func() (*http.Request, error) {
+		return l.newMultipartRequest(ctx, route, method, fields, filename, content)
+	}
+
+	resp, err := l.sendRequest(ctx, method, build, false)
+	if err != nil {
+		return err
+	}
+
+	body, err := readResponse(method, route, resp)
+	return decodePosted(body, err, entity)
+}
+
+// newMultipartRequest builds a request whose body is written by a
+// goroutine as the transport reads it. If the request fails early, the
+// transport closes the body and the goroutine exits on the broken pipe.
+func (l *Lingotek) newMultipartRequest(ctx context.Context, route, method string, fields url.Values, filename string, content io.Reader) (*http.Request, error) {
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+
+	req, err := http.NewRequestWithContext(ctx, method, l.baseURL+route, pr)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.userAgent != "" {
+		req.Header.Set("User-Agent", l.userAgent)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	go func() {
+		pw.CloseWithError(writeMultipart(writer, fields, filename, content))
+	}()
+
+	return req, nil
+}
+
+func writeMultipart(writer *multipart.Writer, fields url.Values, filename string, content io.Reader) error {
+	for key := range fields {
+		err := writer.WriteField(key, fields.Get(key))
+		if err != nil {
+			return err
+		}
+	}
+
+	// Without a filename the part would be read as a plain form value
+	if filename == "" {
+		filename = "content"
+	}
+
+	part, err := writer.CreateFormFile("content", filename)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(part, content)
+	if err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
@@ -0,0 +1,152 @@
+package validate
+
+import (
+	"bytes"
+	"errors"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/CuriousLLC/Lingotek/android"
+	"github.com/CuriousLLC/Lingotek/bundle"
+	"github.com/CuriousLLC/Lingotek/ios"
+	"github.com/CuriousLLC/Lingotek/po"
+	"github.com/CuriousLLC/Lingotek/xliff"
+)
+
+var UnknownFormat = errors.New("Unknown file format")
+
+// File is a source file or one of its translations.
+type File struct {
+	// Name picks the format by its extension: .json, .yml and .yaml are
+	// bundles, .xml is Android resources, .strings and .stringsdict are
+	// iOS strings, .po is gettext and .xlf and .xliff are XLIFF.
+	Name string
+	// Locale is the locale of the content, which bundles may be nested
+	// under.
+	Locale  string
+	Content []byte
+}
+
+// Files validates a translated file against its source file. PO and XLIFF
+// files carry their own source text, so only the translation is read.
+func Files(source, translation File, opts *Options) (*Report, error) {
+	switch strings.ToLower(path.Ext(translation.Name)) {
+	case ".po":
+		return catalog(translation.Content, opts)
+	case ".xlf", ".xliff":
+		return document(translation.Content, opts)
+	}
+
+	sourceStrings, err := keyed(source)
+	if err != nil {
+		return nil, err
+	}
+
+	targetStrings, err := keyed(translation)
+	if err != nil {
+		return nil, err
+	}
+
+	return Compare(sourceStrings, targetStrings, opts), nil
+}
+
+// keyed reads the text of a file of a key value format.
+func keyed(f File) (map[string]string, error) {
+	r := bytes.NewReader(f.Content)
+	values := make(map[string]string)
+	ext := strings.ToLower(path.Ext(f.Name))
+
+	switch ext {
+	case ".json", ".yml", ".yaml":
+		parse := bundle.ParseYAML
+		if ext == ".json" {
+			parse = bundle.ParseJSON
+		}
+
+		b, err := parse(r, f.Locale)
+		if err != nil {
+			return nil, err
+		}
+		return b.Strings(), nil
+	case ".xml":
+		resources, err := android.Parse(r)
+		if err != nil {
+			return nil, err
+		}
+		for _, unit := range resources.Units() {
+			values[unit.Key] = unit.Value
+		}
+	case ".strings":
+		s, err := ios.ParseStrings(r)
+		if err != nil {
+			return nil, err
+		}
+		for _, unit := range s.Units() {
+			values[unit.Key] = unit.Value
+		}
+	case ".stringsdict":
+		d, err := ios.ParseStringsDict(r)
+		if err != nil {
+			return nil, err
+		}
+		for _, unit := range d.Units() {
+			values[unit.Key] = unit.Value
+		}
+	default:
+		return nil, UnknownFormat
+	}
+
+	return values, nil
+}
+
+func catalog(content []byte, opts *Options) (*Report, error) {
+	c, err := po.Parse(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []Segment
+	for _, entry := range c.Entries {
+		if entry.Obsolete {
+			continue
+		}
+
+		if !entry.IsPlural() {
+			segments = append(segments, Segment{entry.Key(), entry.Id, entry.Str})
+			continue
+		}
+
+		// The first form translates the singular msgid, the others the
+		// plural one
+		for i, str := range entry.StrPlural {
+			source := entry.IdPlural
+			if i == 0 {
+				source = entry.Id
+			}
+			segments = append(segments, Segment{entry.Key() + "[" + strconv.Itoa(i) + "]", source, str})
+		}
+	}
+
+	return Validate(segments, opts), nil
+}
+
+func document(content []byte, opts *Options) (*Report, error) {
+	d, err := xliff.Parse(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []Segment
+	for _, file := range d.Files {
+		for _, unit := range file.Units {
+			key := unit.Name
+			if key == "" {
+				key = unit.Id
+			}
+			segments = append(segments, Segment{key, unit.Source, unit.Target})
+		}
+	}
+
+	return Validate(segments, opts), nil
+}
@@ -0,0 +1,218 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// icuMessage is what parseICU found in a message.
+type icuMessage struct {
+	// arguments are the names of all arguments, and simple those of the
+	// arguments without plural or select branches.
+	arguments []string
+	simple    []string
+	// complex is set if the message has plural or select arguments.
+	complex bool
+	// text is the literal text between the arguments.
+	text []string
+	err  error
+}
+
+type icuParser struct {
+	s       string
+	pos     int
+	message icuMessage
+	text    strings.Builder
+}
+
+// parseICU parses an ICU MessageFormat message.
+func parseICU(s string) icuMessage {
+	p := icuParser{s: s}
+	p.message.err = p.parseMessage(0, false)
+	if p.message.err == nil && p.pos < len(s) {
+		p.message.err = errors.New("unexpected }")
+	}
+
+	p.flush()
+	p.message.arguments = unique(p.message.arguments)
+	p.message.simple = unique(p.message.simple)
+	return p.message
+}
+
+func (p *icuParser) flush() {
+	if p.text.Len() > 0 {
+		p.message.text = append(p.message.text, p.text.String())
+		p.text.Reset()
+	}
+}
+
+// parseMessage parses text and arguments up to the } closing a branch,
+// or the end of the message at depth 0.
+func (p *icuParser) parseMessage(depth int, inPlural bool) error {
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+
+		switch {
+		case c == '\'':
+			p.parseQuote(inPlural)
+		case c == '{':
+			p.flush()
+			if err := p.parseArgument(depth); err != nil {
+				return err
+			}
+		case c == '}':
+			if depth == 0 {
+				return nil
+			}
+			p.flush()
+			return nil
+		case c == '#' && inPlural:
+			p.flush()
+			p.pos++
+		default:
+			p.text.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	if depth > 0 {
+		return errors.New("unclosed {")
+	}
+
+	return nil
+}
+
+// parseQuote handles an apostrophe: ” is a literal apostrophe, and one
+// before a special character quotes text up to the next apostrophe.
+func (p *icuParser) parseQuote(inPlural bool) {
+	p.pos++
+	if p.pos < len(p.s) && p.s[p.pos] == '\'' {
+		p.text.WriteByte('\'')
+		p.pos++
+		return
+	}
+
+	if p.pos == len(p.s) || !strings.ContainsRune("{}|", rune(p.s[p.pos])) && !(inPlural && p.s[p.pos] == '#') {
+		p.text.WriteByte('\'')
+		return
+	}
+
+	end := strings.IndexByte(p.s[p.pos:], '\'')
+	if end < 0 {
+		end = len(p.s) - p.pos
+	}
+	p.text.WriteString(p.s[p.pos : p.pos+end])
+	p.pos = min(p.pos+end+1, len(p.s))
+}
+
+func (p *icuParser) skipSpace() {
+	for p.pos < len(p.s) && unicode.IsSpace(rune(p.s[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *icuParser) word() string {
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := rune(p.s[p.pos])
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' && c != '=' && c != ':' && c != '.' && c != '-' {
+			break
+		}
+		p.pos++
+	}
+
+	return p.s[start:p.pos]
+}
+
+func (p *icuParser) expect(c byte) error {
+	p.skipSpace()
+	if p.pos == len(p.s) || p.s[p.pos] != c {
+		return fmt.Errorf("expected %q at %d", c, p.pos)
+	}
+
+	p.pos++
+	return nil
+}
+
+// parseArgument parses {name}, {name, type, style} or the branches of
+// {name, plural|selectordinal|select, ...}.
+func (p *icuParser) parseArgument(depth int) error {
+	p.pos++
+	p.skipSpace()
+
+	name := p.word()
+	if name == "" {
+		return fmt.Errorf("expected an argument name at %d", p.pos)
+	}
+	p.message.arguments = append(p.message.arguments, name)
+
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == '}' {
+		p.pos++
+		p.message.simple = append(p.message.simple, name)
+		return nil
+	}
+
+	if err := p.expect(','); err != nil {
+		return err
+	}
+	p.skipSpace()
+	kind := p.word()
+	p.skipSpace()
+
+	switch kind {
+	case "plural", "selectordinal", "select":
+	case "number", "date", "time", "spellout", "ordinal", "duration":
+		// The style is opaque, skip to the closing brace
+		end := strings.IndexByte(p.s[p.pos:], '}')
+		if end < 0 {
+			return errors.New("unclosed {")
+		}
+		p.pos += end + 1
+		p.message.simple = append(p.message.simple, name)
+		return nil
+	default:
+		return fmt.Errorf("unknown argument type %q", kind)
+	}
+
+	p.message.complex = true
+	if err := p.expect(','); err != nil {
+		return err
+	}
+
+	branches := make(map[string]bool)
+	for {
+		p.skipSpace()
+		if p.pos < len(p.s) && p.s[p.pos] == '}' {
+			p.pos++
+			break
+		}
+
+		selector := p.word()
+		if selector == "" {
+			return fmt.Errorf("expected a selector at %d", p.pos)
+		}
+		if strings.HasPrefix(selector, "offset:") && kind != "select" {
+			continue
+		}
+		branches[selector] = true
+
+		if err := p.expect('{'); err != nil {
+			return err
+		}
+		if err := p.parseMessage(depth+1, kind != "select"); err != nil {
+			return err
+		}
+		if err := p.expect('}'); err != nil {
+			return err
+		}
+	}
+
+	if !branches["other"] {
+		return fmt.Errorf("%s argument %s has no other branch", kind, name)
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package validate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// placeholder matches the common placeholder syntaxes, tried left to
+// right: {{name}}, %{name}, ${name}, Python's %(name)s, printf and
+// String Format Specifiers like %1$s or %@, and ICU and .NET arguments
+// like {0} or {name}. The space flag of printf is left out, as "50% off"
+// is far more common than "% d".
+var placeholder = regexp.MustCompile(strings.Join([]string{
+	`\{\{\s*[^{}]+?\s*\}\}`,
+	`%\{[^{}]+\}`,
+	`\$\{[^{}]+\}`,
+	`%\([^()]+\)[-#0 +]*\d*(?:\.\d+)?[a-zA-Z]`,
+	`%(?:\d+\$)?[-#0+']*(?:\d+|\*)?(?:\.(?:\d+|\*))?(?:hh|h|ll|l|q|z|t|j|L)?[diouxXeEfFgGcCsSaAp@]`,
+	`\{\s*[\w.]+\s*(?:,\s*(?:number|date|time)\s*(?:,[^{}]*)?)?\}`,
+}, "|"))
+
+var spaces = regexp.MustCompile(`\s+`)
+
+// placeholders returns the placeholders of s in order, with spacing
+// removed so that {{ name }} and {{name}} are the same. A placeholder
+// used twice is returned twice. The text of an ICU message is searched
+// without its plural and select structure, whose branches aren't
+// placeholders.
+func placeholders(s string, patterns []*regexp.Regexp) []string {
+	text := s
+	var found []string
+
+	if message := parseICU(s); message.err == nil && message.complex {
+		text = strings.Join(message.text, "\x00")
+		for _, argument := range message.simple {
+			found = append(found, "{"+argument+"}")
+		}
+	}
+
+	for _, pattern := range append([]*regexp.Regexp{placeholder}, patterns...) {
+		for _, match := range pattern.FindAllString(text, -1) {
+			found = append(found, spaces.ReplaceAllString(match, ""))
+		}
+	}
+
+	return found
+}
+
+var tag = regexp.MustCompile(`<(/?)([a-zA-Z][\w:.-]*)(?:\s[^<>]*?)?(/?)>`)
+
+// tags returns the tags of s in order as "<b>", "</b>" or "<br/>", and
+// an error if they aren't balanced. HTML's void elements such as <br>
+// don't need closing.
+func tags(s string) ([]string, error) {
+	var found, open []string
+
+	for _, match := range tag.FindAllStringSubmatch(s, -1) {
+		closing, name, selfClosing := match[1] == "/", match[2], match[3] == "/"
+
+		switch {
+		case selfClosing || !closing && voidElements[strings.ToLower(name)]:
+			found = append(found, "<"+name+"/>")
+		case closing:
+			found = append(found, "</"+name+">")
+			if len(open) == 0 || open[len(open)-1] != name {
+				return found, fmt.Errorf("unexpected </%s>", name)
+			}
+			open = open[:len(open)-1]
+		default:
+			found = append(found, "<"+name+">")
+			open = append(open, name)
+		}
+	}
+
+	if len(open) > 0 {
+		return found, fmt.Errorf("unclosed <%s>", open[len(open)-1])
+	}
+
+	return found, nil
+}
+
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+func unique(strs []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, s := range strs {
+		if !seen[s] {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,355 @@
+// Package validate checks downloaded translations against their source
+// before they are shipped: placeholders such as %s, {0} or {{name}} must
+// be kept, markup must stay balanced, ICU messages must still parse, and
+// whitespace and length should roughly match.
+//
+// Segments come from the format packages, e.g. the units of an xliff
+// document or the strings of a bundle, or Files reads them from a source
+// file and its translation. The resulting Report can stop WriteFile from
+// writing a broken translation.
+package validate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Check names a kind of problem.
+type Check string
+
+const (
+	Empty        Check = "empty"
+	Placeholders Check = "placeholders"
+	Markup       Check = "markup"
+	ICU          Check = "icu"
+	Whitespace   Check = "whitespace"
+	Length       Check = "length"
+)
+
+type Severity int
+
+const (
+	Warning Severity = iota
+	Error
+)
+
+func (s Severity) String() string {
+	if s == Error {
+		return "error"
+	}
+
+	return "warning"
+}
+
+// Segment is a source text and its translation.
+type Segment struct {
+	Key    string
+	Source string
+	Target string
+}
+
+// Issue is a problem found in one segment. Missing and Extra list the
+// placeholders or tags that were dropped or added.
+type Issue struct {
+	Key      string
+	Check    Check
+	Severity Severity
+	Message  string
+	Missing  []string
+	Extra    []string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s: %s", i.Key, i.Severity, i.Message)
+}
+
+// Report lists the issues of a validation.
+type Report struct {
+	Segments int
+	Issues   []Issue
+}
+
+// OK reports whether no issue is an error.
+func (r *Report) OK() bool {
+	return len(r.Errors()) == 0
+}
+
+func (r *Report) Errors() []Issue {
+	return r.filter(Error)
+}
+
+func (r *Report) Warnings() []Issue {
+	return r.filter(Warning)
+}
+
+func (r *Report) filter(severity Severity) []Issue {
+	var issues []Issue
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			issues = append(issues, issue)
+		}
+	}
+
+	return issues
+}
+
+// Err returns a *ValidationError if the report has errors, and nil
+// otherwise.
+func (r *Report) Err() error {
+	if r.OK() {
+		return nil
+	}
+
+	return &ValidationError{r}
+}
+
+// ValidationError is returned for translations that failed validation.
+type ValidationError struct {
+	Report *Report
+}
+
+func (e *ValidationError) Error() string {
+	errors := e.Report.Errors()
+	message := fmt.Sprintf("validation failed with %d errors", len(errors))
+
+	// The first few are enough to see what's wrong
+	for i, issue := range errors {
+		if i == 3 {
+			message += "; ..."
+			break
+		}
+		message += "; " + issue.String()
+	}
+
+	return message
+}
+
+// Options tune the checks. The zero value uses the defaults.
+type Options struct {
+	// A target shorter than MinLengthRatio or longer than MaxLengthRatio
+	// times its source is reported. Defaults to 0.3 and 3.
+	MinLengthRatio float64
+	MaxLengthRatio float64
+	// MinLength is the length in characters below which the length isn't
+	// checked, as short strings vary too much. Defaults to 10.
+	MinLength int
+	// Patterns match further placeholder syntaxes.
+	Patterns []*regexp.Regexp
+	// Disable skips checks.
+	Disable []Check
+	// Strict reports every issue as an error.
+	Strict bool
+}
+
+func (o *Options) withDefaults() Options {
+	opts := Options{}
+	if o != nil {
+		opts = *o
+	}
+
+	if opts.MinLengthRatio == 0 {
+		opts.MinLengthRatio = 0.3
+	}
+	if opts.MaxLengthRatio == 0 {
+		opts.MaxLengthRatio = 3
+	}
+	if opts.MinLength == 0 {
+		opts.MinLength = 10
+	}
+
+	return opts
+}
+
+func (o *Options) enabled(check Check) bool {
+	for _, disabled := range o.Disable {
+		if disabled == check {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Validate checks every segment.
+func Validate(segments []Segment, opts *Options) *Report {
+	o := opts.withDefaults()
+	report := Report{Segments: len(segments)}
+
+	for _, segment := range segments {
+		for _, issue := range o.check(segment) {
+			if o.enabled(issue.Check) {
+				if o.Strict {
+					issue.Severity = Error
+				}
+				report.Issues = append(report.Issues, issue)
+			}
+		}
+	}
+
+	return &report
+}
+
+// Compare validates the translations of a set of keyed strings, such as
+// those of a bundle. Keys missing from target are reported as empty.
+func Compare(source, target map[string]string, opts *Options) *Report {
+	keys := make([]string, 0, len(source))
+	for key := range source {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	segments := make([]Segment, len(keys))
+	for i, key := range keys {
+		segments[i] = Segment{key, source[key], target[key]}
+	}
+
+	return Validate(segments, opts)
+}
+
+func (o *Options) check(s Segment) []Issue {
+	var issues []Issue
+	add := func(check Check, severity Severity, message string, missing, extra []string) {
+		issues = append(issues, Issue{s.Key, check, severity, message, missing, extra})
+	}
+
+	if strings.TrimSpace(s.Source) == "" {
+		return nil
+	}
+	if strings.TrimSpace(s.Target) == "" {
+		add(Empty, Error, "translation is empty", nil, nil)
+		return issues
+	}
+
+	sourcePlaceholders, targetPlaceholders := placeholders(s.Source, o.Patterns), placeholders(s.Target, o.Patterns)
+	if message := parseICU(s.Source); message.err == nil && message.complex {
+		// Each plural or select branch repeats the placeholders, and
+		// languages differ in their number of branches
+		sourcePlaceholders, targetPlaceholders = unique(sourcePlaceholders), unique(targetPlaceholders)
+	}
+
+	missing, extra := diff(sourcePlaceholders, targetPlaceholders)
+	if len(missing) > 0 || len(extra) > 0 {
+		add(Placeholders, Error, describe("placeholders", missing, extra), missing, extra)
+	}
+
+	// A source whose angle brackets aren't balanced markup, such as
+	// "Press <Enter>" or "List<String>", leaves nothing to compare with
+	if sourceTags, err := tags(s.Source); err == nil {
+		targetTags, err := tags(s.Target)
+		if err != nil {
+			add(Markup, Error, err.Error(), nil, nil)
+		} else if missing, extra := diff(sourceTags, targetTags); len(missing) > 0 || len(extra) > 0 {
+			add(Markup, Error, describe("tags", missing, extra), missing, extra)
+		}
+	}
+
+	if source := parseICU(s.Source); source.err == nil && source.complex {
+		target := parseICU(s.Target)
+		if target.err != nil {
+			add(ICU, Error, "invalid ICU message: "+target.err.Error(), nil, nil)
+		} else if missing, extra := diff(source.arguments, target.arguments); len(missing) > 0 || len(extra) > 0 {
+			add(ICU, Error, describe("ICU arguments", missing, extra), missing, extra)
+		}
+	}
+
+	if leading(s.Source) != leading(s.Target) {
+		add(Whitespace, Warning, fmt.Sprintf("leading whitespace %q became %q", leading(s.Source), leading(s.Target)), nil, nil)
+	}
+	if trailing(s.Source) != trailing(s.Target) {
+		add(Whitespace, Warning, fmt.Sprintf("trailing whitespace %q became %q", trailing(s.Source), trailing(s.Target)), nil, nil)
+	}
+
+	sourceLength := utf8.RuneCountInString(s.Source)
+	if sourceLength >= o.MinLength {
+		ratio := float64(utf8.RuneCountInString(s.Target)) / float64(sourceLength)
+		if ratio < o.MinLengthRatio || ratio > o.MaxLengthRatio {
+			add(Length, Warning, fmt.Sprintf("translation is %.1f times as long as the source", ratio), nil, nil)
+		}
+	}
+
+	return issues
+}
+
+func leading(s string) string {
+	return s[:len(s)-len(strings.TrimLeft(s, " \t\r\n"))]
+}
+
+func trailing(s string) string {
+	return s[len(strings.TrimRight(s, " \t\r\n")):]
+}
+
+// diff returns the elements of source missing from target and those
+// only in target, counting duplicates.
+func diff(source, target []string) (missing, extra []string) {
+	counts := make(map[string]int)
+	for _, s := range target {
+		counts[s]++
+	}
+
+	for _, s := range source {
+		if counts[s] > 0 {
+			counts[s]--
+		} else {
+			missing = append(missing, s)
+		}
+	}
+
+	for _, s := range target {
+		if counts[s] > 0 {
+			counts[s]--
+			extra = append(extra, s)
+		}
+	}
+
+	return missing, extra
+}
+
+func describe(what string, missing, extra []string) string {
+	var parts []string
+	if len(missing) > 0 {
+		parts = append(parts, "missing "+what+" "+strings.Join(missing, " "))
+	}
+	if len(extra) > 0 {
+		parts = append(parts, "unexpected "+what+" "+strings.Join(extra, " "))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// WriteFile writes a translation unless its report has errors, in which
+// case the file is left alone and the report's error is returned. A nil
+// report writes it unconditionally. The file is replaced atomically, so
+// readers never see half of it.
+func WriteFile(path string, content []byte, report *Report) error {
+	if report != nil {
+		if err := report.Err(); err != nil {
+			return err
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+
+	return err
+}
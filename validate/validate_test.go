@@ -0,0 +1,194 @@
+package validate
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func issues(report *Report) map[string][]Check {
+	found := make(map[string][]Check)
+	for _, issue := range report.Issues {
+		found[issue.Key] = append(found[issue.Key], issue.Check)
+	}
+
+	return found
+}
+
+func TestValidate(t *testing.T) {
+	segments := []Segment{
+		{"ok", "Hello, %s! You have {0} new <b>messages</b>.", "¡Hola, %s! Tienes {0} <b>mensajes</b> nuevos."},
+		{"reordered", "%1$s sent %2$d files", "%2$d archivos enviados por %1$s"},
+		{"dropped", "Delete %s?", "¿Eliminar?"},
+		{"added", "Saved", "Guardado {{name}}"},
+		{"i18next", "Hi {{ name }}, %{count} left", "Hola {{name}}, quedan %{count}"},
+		{"unbalanced", "Press <b>Save</b>", "Pulse <b>Guardar"},
+		{"swapped", "<b><i>Note</i></b>", "<i><b>Nota</i></b>"},
+		{"void", "Line<br>break", "Línea<br/>salto"},
+		{"halved", "<b>Save</b> or <b>Cancel</b>", "<b>Guardar</b> o Cancelar"},
+		{"key", "Press <Enter> to continue", "Pulse <Enter> para continuar"},
+		{"generic", "Returns a List<String>", "Devuelve una List<String>"},
+		{"space", "Name: ", "Nombre:"},
+		{"long", "Cancel the upload", "Cancelar la carga de todos los archivos seleccionados por el usuario hasta ahora"},
+		{"empty", "Open", ""},
+		{"percent", "100% done", "100 % hecho"},
+		{"repeated", "%s of %s files", "%s Dateien"},
+		{"repeated index", "{0} of {0}", "{0} von {0}"},
+		{"dropped index", "{0} or {0}", "{0}"},
+	}
+
+	report := Validate(segments, nil)
+	expected := map[string][]Check{
+		"dropped":       {Placeholders},
+		"added":         {Placeholders},
+		"unbalanced":    {Markup},
+		"swapped":       {Markup},
+		"halved":        {Markup},
+		"space":         {Whitespace},
+		"long":          {Length},
+		"empty":         {Empty},
+		"repeated":      {Placeholders},
+		"dropped index": {Placeholders},
+	}
+	if found := issues(report); !reflect.DeepEqual(found, expected) {
+		t.Errorf("Expected issues %v, got %v", expected, found)
+	}
+
+	if report.Segments != len(segments) || report.OK() || len(report.Errors()) != 8 || len(report.Warnings()) != 2 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+
+	for _, issue := range report.Issues {
+		if issue.Key == "dropped" && !reflect.DeepEqual(issue.Missing, []string{"%s"}) {
+			t.Errorf("Expected %%s to be missing, got %+v", issue)
+		}
+		if issue.Key == "repeated" && !reflect.DeepEqual(issue.Missing, []string{"%s"}) {
+			t.Errorf("Expected one %%s to be missing, got %+v", issue)
+		}
+		if issue.Key == "added" && !reflect.DeepEqual(issue.Extra, []string{"{{name}}"}) {
+			t.Errorf("Expected {{name}} to be extra, got %+v", issue)
+		}
+		if issue.Key == "halved" && !reflect.DeepEqual(issue.Missing, []string{"<b>", "</b>"}) {
+			t.Errorf("Expected a <b> pair to be missing, got %+v", issue)
+		}
+	}
+}
+
+func TestICU(t *testing.T) {
+	source := "{count, plural, one {# file in {folder}} other {# files in {folder}}}"
+	tests := []struct {
+		target   string
+		expected []Check
+	}{
+		// Russian has more forms, and the branches aren't placeholders
+		{"{count, plural, one {# файл в {folder}} few {# файла в {folder}} many {# файлов в {folder}} other {# файла в {folder}}}", nil},
+		{"{count, plural, one {# archivo en {folder}} other {# archivos}}", nil},
+		{"{count, plural, one {# archivo} other {# archivos}}", []Check{Placeholders, ICU}},
+		{"{count, plural, one {# archivo en {folder}}}", []Check{ICU}},
+		{"{count, plural, one {# archivo en {folder}} other {# archivos en {folder}}", []Check{ICU}},
+		{"{total, plural, one {# archivo en {folder}} other {# archivos en {folder}}}", []Check{ICU}},
+	}
+
+	for _, test := range tests {
+		report := Validate([]Segment{{"files", source, test.target}}, &Options{Disable: []Check{Length}})
+		if found := issues(report)["files"]; !reflect.DeepEqual(found, test.expected) {
+			t.Errorf("%s: expected %v, got %v: %v", test.target, test.expected, found, report.Issues)
+		}
+	}
+
+	message := "{gender, select, female {She} male {He} other {They}} replied"
+	report := Validate([]Segment{{"reply", message, "{gender, select, female {Ella} other {Elle}} respondió"}}, nil)
+	if !report.OK() {
+		t.Errorf("Expected select to be valid, got %v", report.Issues)
+	}
+
+	if message := parseICU("It''s '{literal}' text"); message.err != nil || message.complex || len(message.arguments) != 0 {
+		t.Errorf("Unexpected quoting result %+v", message)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	segments := []Segment{
+		{"custom", "Hello [[name]]", "Hola amigo"},
+		{"space", "Name: ", "Nombre:"},
+	}
+
+	report := Validate(segments, &Options{Patterns: []*regexp.Regexp{regexp.MustCompile(`\[\[\w+\]\]`)}})
+	if found := issues(report); !reflect.DeepEqual(found["custom"], []Check{Placeholders}) {
+		t.Errorf("Expected the custom placeholder to be checked, got %v", found)
+	}
+	if len(report.Errors()) != 1 {
+		t.Errorf("Expected the whitespace issue to be a warning, got %v", report.Errors())
+	}
+
+	report = Validate(segments, &Options{Strict: true, Disable: []Check{Placeholders}})
+	if errs := report.Errors(); len(errs) != 1 || errs[0].Check != Whitespace {
+		t.Errorf("Expected only the whitespace issue as an error, got %v", errs)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	source := map[string]string{"a": "Hello %s", "b": "Bye"}
+	target := map[string]string{"a": "Hola %s"}
+
+	report := Compare(source, target, nil)
+	if found := issues(report); !reflect.DeepEqual(found, map[string][]Check{"b": {Empty}}) {
+		t.Errorf("Expected the missing key to be empty, got %v", found)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "es.json")
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report := Validate([]Segment{{"a", "Delete %s?", "¿Eliminar?"}}, nil)
+	err := WriteFile(path, []byte("new"), report)
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Report != report {
+		t.Fatalf("Expected a ValidationError, got %v", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "old" {
+		t.Errorf("Expected the file to be left alone, got %q", content)
+	}
+
+	report = Validate([]Segment{{"a", "Delete %s?", "¿Eliminar %s?"}}, nil)
+	if err := WriteFile(path, []byte("new"), report); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "new" {
+		t.Errorf("Expected the file to be written, got %q", content)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	source := File{"en.yml", "en", []byte("en:\n  hello: \"Hello, %{name}!\"\n  bye: Bye\n")}
+	translation := File{"fr.yml", "fr", []byte("fr:\n  hello: \"Bonjour !\"\n  bye: Au revoir\n")}
+
+	report, err := Files(source, translation, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found := issues(report); !reflect.DeepEqual(found, map[string][]Check{"hello": {Placeholders}}) {
+		t.Errorf("Expected the dropped placeholder to be reported, got %v", found)
+	}
+
+	// A PO file holds its own source text
+	catalog := []byte("msgid \"%d file\"\nmsgid_plural \"%d files\"\nmsgstr[0] \"%d fichier\"\nmsgstr[1] \"fichiers\"\n")
+	report, err = Files(File{}, File{Name: "fr.po", Content: catalog}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found := issues(report); !reflect.DeepEqual(found, map[string][]Check{"%d file[1]": {Placeholders}}) {
+		t.Errorf("Expected the plural form to be reported, got %v", found)
+	}
+
+	if _, err := Files(File{Name: "en.txt"}, File{Name: "fr.txt"}, nil); err != UnknownFormat {
+		t.Errorf("Expected UnknownFormat, got %v", err)
+	}
+}
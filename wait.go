@@ -0,0 +1,202 @@
+package lingotek
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+var WaitTimeout = errors.New("Timed out waiting for completion")
+
+// WaitOptions controls how WaitForDocument and WaitForTranslation poll.
+// The zero value polls every 5 seconds, backing off to once a minute,
+// until ctx is done.
+type WaitOptions struct {
+	// Interval is the delay before the second poll.
+	Interval time.Duration
+	// MaxInterval caps the delay as it grows by Multiplier with every poll.
+	MaxInterval time.Duration
+	Multiplier  float64
+	// Timeout gives up with WaitTimeout after this long. Zero waits as
+	// long as ctx allows.
+	Timeout time.Duration
+	// OnProgress is called with the percentage done after every poll.
+	OnProgress func(percent int)
+}
+
+func (o *WaitOptions) withDefaults() WaitOptions {
+	opts := WaitOptions{}
+	if o != nil {
+		opts = *o
+	}
+
+	if opts.Interval <= 0 {
+		opts.Interval = 5 * time.Second
+	}
+	if opts.MaxInterval <= 0 {
+		opts.MaxInterval = time.Minute
+	}
+	if opts.MaxInterval < opts.Interval {
+		opts.MaxInterval = opts.Interval
+	}
+	if opts.Multiplier < 1 {
+		opts.Multiplier = 1.5
+	}
+
+	return opts
+}
+
+// poll calls check until it reports done, sleeping between calls as opts
+// describes. check returns the percentage done for progress reports.
+func poll(ctx context.Context, o *WaitOptions, check func(context.Context) (int, bool, error)) error {
+	opts := o.withDefaults()
+
+	parent := ctx
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	// Only our own timeout is reported as WaitTimeout; the caller's
+	// cancellation comes back as is.
+	timedOut := func(err error) error {
+		if parent.Err() == nil && ctx.Err() != nil {
+			return WaitTimeout
+		}
+		return err
+	}
+
+	interval := opts.Interval
+	for {
+		percent, done, err := check(ctx)
+		if err != nil {
+			return timedOut(err)
+		}
+
+		if opts.OnProgress != nil {
+			opts.OnProgress(percent)
+		}
+
+		if done {
+			return nil
+		}
+
+		err = sleep(ctx, interval)
+		if err != nil {
+			return timedOut(err)
+		}
+
+		interval = time.Duration(float64(interval) * opts.Multiplier)
+		if interval > opts.MaxInterval {
+			interval = opts.MaxInterval
+		}
+	}
+}
+
+// getImporting is getEntity for resources of a document that may still be
+// importing, when Lingotek answers 202 with no entity. That answer is
+// reported as ErrImporting.
+func (l *Lingotek) getImporting(ctx context.Context, route string, params *url.Values, entity interface{}) error {
+	resp, err := l.send(ctx, route, "GET", params)
+	if err != nil {
+		return err
+	}
+
+	body, err := readResponse("GET", route, resp)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusAccepted {
+		return ErrImporting
+	}
+
+	return json.Unmarshal(body, entity)
+}
+
+// getImported is getEntity for resources that may not exist yet. Right
+// after an upload Lingotek answers 202 or 404 while the document is still
+// being imported, which is reported as not ready rather than an error.
+func (l *Lingotek) getImported(ctx context.Context, route string, params *url.Values, entity interface{}) (bool, error) {
+	err := l.getImporting(ctx, route, params, entity)
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrImporting) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// WaitForDocument polls until document has been imported and analyzed,
+// and returns it with its final status.
+func (l *Lingotek) WaitForDocument(ctx context.Context, document *Document, opts *WaitOptions) (*Document, error) {
+	if document.Property.Id == "" {
+		return nil, IdRequired
+	}
+
+	var current Document
+	check := func(ctx context.Context) (int, bool, error) {
+		ready, err := l.getImported(ctx, "document/"+document.Property.Id, nil, &current)
+		if err != nil || !ready {
+			return 0, false, err
+		}
+
+		progress := int(current.Status.Property.Progress)
+		return progress, progress >= 100, nil
+	}
+
+	err := poll(ctx, opts, check)
+	if err != nil {
+		return nil, err
+	}
+
+	return &current, nil
+}
+
+// WaitForTranslation polls until the localeCode translation of document
+// is complete, and returns it. The translation must have been requested
+// with AddTranslation first, or WaitForTranslation waits until it times out.
+func (l *Lingotek) WaitForTranslation(ctx context.Context, document *Document, localeCode string, opts *WaitOptions) (*Translation, error) {
+	if document.Property.Id == "" {
+		return nil, IdRequired
+	}
+
+	localeCode = CanonicalTag(localeCode)
+
+	// A document has few enough translations to fit on one page
+	v := url.Values{}
+	v.Set("limit", "100")
+
+	var current Translation
+	check := func(ctx context.Context) (int, bool, error) {
+		var response Response
+		ready, err := l.getImported(ctx, "document/"+document.Property.Id+"/translation", &v, &response)
+		if err != nil || !ready || response.Properties.Size == 0 {
+			return 0, false, err
+		}
+
+		var translations []Translation
+		err = json.Unmarshal(response.Entities, &translations)
+		if err != nil {
+			return 0, false, err
+		}
+
+		for _, translation := range translations {
+			if CanonicalTag(translation.Property.LocaleCode) == localeCode {
+				current = translation
+				return translation.Property.PercentComplete, translation.IsComplete(), nil
+			}
+		}
+
+		return 0, false, nil
+	}
+
+	err := poll(ctx, opts, check)
+	if err != nil {
+		return nil, err
+	}
+
+	return &current, nil
+}
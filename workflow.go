@@ -0,0 +1,155 @@
+package lingotek
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sort"
+)
+
+type WorkflowProperty struct {
+	Id          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// Workflow is the review process translations go through. Its phases are
+// sorted by their order.
+type Workflow struct {
+	Property WorkflowProperty
+	Phases   []Phase
+	Links    []Link
+}
+
+func (w *Workflow) UnmarshalJSON(data []byte) error {
+	wObj := make(map[string]json.RawMessage)
+	var entities []json.RawMessage
+
+	err := json.Unmarshal(data, &wObj)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(wObj["properties"], &w.Property)
+	if err != nil {
+		return err
+	}
+
+	if links, ok := wObj["links"]; ok {
+		err = json.Unmarshal(links, &w.Links)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, ok := wObj["entities"]; !ok {
+		return nil
+	}
+
+	err = json.Unmarshal(wObj["entities"], &entities)
+	if err != nil {
+		return err
+	}
+
+	for _, raw := range entities {
+		var entity Entity
+
+		err = json.Unmarshal(raw, &entity)
+		if err != nil {
+			return err
+		}
+
+		phases, err := unmarshalPhases(entity.Class, raw)
+		if err != nil {
+			return err
+		}
+		w.Phases = append(w.Phases, phases...)
+	}
+
+	sortPhases(w.Phases)
+
+	return nil
+}
+
+// unmarshalPhases decodes an entity holding phases. Phases are either
+// listed directly, or wrapped in a collection whose entities may be
+// missing when it's empty. Other entities yield no phases.
+func unmarshalPhases(class []string, raw json.RawMessage) ([]Phase, error) {
+	var phases []Phase
+
+	switch {
+	case hasClass(class, "phases"):
+		var collection struct {
+			Entities json.RawMessage `json:"entities"`
+		}
+
+		err := json.Unmarshal(raw, &collection)
+		if err != nil || len(collection.Entities) == 0 {
+			return nil, err
+		}
+
+		err = json.Unmarshal(collection.Entities, &phases)
+		if err != nil {
+			return nil, err
+		}
+	case hasClass(class, "phase"):
+		var phase Phase
+
+		err := json.Unmarshal(raw, &phase)
+		if err != nil {
+			return nil, err
+		}
+		phases = append(phases, phase)
+	}
+
+	return phases, nil
+}
+
+func sortPhases(phases []Phase) {
+	sort.SliceStable(phases, func(i, j int) bool {
+		return phases[i].Property.Order < phases[j].Property.Order
+	})
+}
+
+func hasClass(classes []string, class string) bool {
+	for _, c := range classes {
+		if c == class {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l *Lingotek) GetWorkflow(id string) (*Workflow, error) {
+	return l.GetWorkflowContext(context.Background(), id)
+}
+
+func (l *Lingotek) GetWorkflowContext(ctx context.Context, id string) (*Workflow, error) {
+	if id == "" {
+		return nil, IdRequired
+	}
+
+	var workflow Workflow
+
+	err := l.getEntity(ctx, "workflow/"+id, nil, &workflow)
+	return &workflow, err
+}
+
+// WorkflowsPager returns a Pager over the workflows available to a community.
+func (l *Lingotek) WorkflowsPager(communityId string, opts *ListOptions) *Pager[Workflow] {
+	params := url.Values{}
+	params.Set("community_id", communityId)
+
+	return newPager[Workflow](l, "workflow", params, opts)
+}
+
+func (l *Lingotek) ListWorkflows(communityId string, opts *ListOptions) (<-chan Workflow, <-chan error) {
+	return l.ListWorkflowsContext(context.Background(), communityId, opts)
+}
+
+// ListWorkflowsContext streams the workflows available to a community.
+// Cancelling ctx stops the pagination and aborts any request in flight.
+func (l *Lingotek) ListWorkflowsContext(ctx context.Context, communityId string, opts *ListOptions) (<-chan Workflow, <-chan error) {
+	return stream(ctx, l.WorkflowsPager(communityId, opts), nil)
+}
@@ -0,0 +1,269 @@
+// Package xliff reads and writes XLIFF 1.2 and 2.0, the exchange format
+// Lingotek and most CAT tools use for translated documents.
+//
+// Source and target content is kept as XML, so inline markup such as
+// <g>, <x/> or <pc> survives a round trip. Use Escape to turn plain text
+// into content and Text to get the plain text back.
+package xliff
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+
+	lingotek "github.com/CuriousLLC/Lingotek"
+)
+
+// Format is the Lingotek format name of XLIFF documents.
+const Format = "XLIFF"
+
+const (
+	Version12 = "1.2"
+	Version20 = "2.0"
+)
+
+var UnsupportedVersion = errors.New("Unsupported XLIFF version")
+var NotXLIFF = errors.New("Not an XLIFF document")
+
+// State is the translation state of a unit, using the XLIFF 2.0 values.
+// XLIFF 1.2 states are mapped onto these when parsing and back when
+// writing.
+type State string
+
+const (
+	StateInitial    State = "initial"
+	StateTranslated State = "translated"
+	StateReviewed   State = "reviewed"
+	StateFinal      State = "final"
+)
+
+var stateOrder = map[State]int{
+	StateInitial:    0,
+	StateTranslated: 1,
+	StateReviewed:   2,
+	StateFinal:      3,
+}
+
+// Document is a parsed XLIFF document. An empty Version is written as
+// XLIFF 1.2.
+type Document struct {
+	Version string
+	Files   []File
+}
+
+// File is one <file> of a document. XLIFF 2.0 sets the languages on the
+// document rather than per file, so they are copied to every file.
+type File struct {
+	// Id is only used by XLIFF 2.0, which numbers files without one.
+	Id             string
+	Original       string
+	SourceLanguage string
+	TargetLanguage string
+	Units          []Unit
+}
+
+// Unit is a translatable unit, a <trans-unit> in XLIFF 1.2 and a <unit>
+// in XLIFF 2.0. Units inside groups are flattened into their file. The
+// segments of an XLIFF 2.0 unit are joined, with the lowest state of
+// any segment.
+type Unit struct {
+	Id string
+	// Name is the resource key, "resname" in 1.2 and "name" in 2.0.
+	Name   string
+	Source string
+	Target string
+	Notes  []string
+	State  State
+}
+
+// NewDocument returns an empty document with a single file.
+func NewDocument(version, original, sourceLanguage, targetLanguage string) *Document {
+	file := File{
+		Original:       original,
+		SourceLanguage: sourceLanguage,
+		TargetLanguage: targetLanguage,
+	}
+	if version == Version20 {
+		file.Id = "f1"
+	}
+
+	return &Document{Version: version, Files: []File{file}}
+}
+
+// Add appends a unit holding the plain text source.
+func (f *File) Add(id, source string) *Unit {
+	f.Units = append(f.Units, Unit{Id: id, Source: Escape(source), State: StateInitial})
+	return &f.Units[len(f.Units)-1]
+}
+
+// Unit returns the unit with the given ID, or nil.
+func (f *File) Unit(id string) *Unit {
+	for i := range f.Units {
+		if f.Units[i].Id == id {
+			return &f.Units[i]
+		}
+	}
+
+	return nil
+}
+
+// SourceText returns the source without markup.
+func (u *Unit) SourceText() string {
+	return Text(u.Source)
+}
+
+// TargetText returns the target without markup.
+func (u *Unit) TargetText() string {
+	return Text(u.Target)
+}
+
+var escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Escape turns plain text into content.
+func Escape(text string) string {
+	return escaper.Replace(text)
+}
+
+// Text strips the markup from content, leaving its plain text. Content
+// that isn't well-formed is returned unchanged.
+func Text(content string) string {
+	var text strings.Builder
+	decoder := xml.NewDecoder(strings.NewReader("<x>" + content + "</x>"))
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return text.String()
+		}
+		if err != nil {
+			return content
+		}
+
+		if data, ok := token.(xml.CharData); ok {
+			text.Write(data)
+		}
+	}
+}
+
+// Parse reads an XLIFF 1.2 or 2.0 document.
+func Parse(r io.Reader) (*Document, error) {
+	decoder := xml.NewDecoder(r)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, NotXLIFF
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local != "xliff" {
+			return nil, NotXLIFF
+		}
+
+		switch version(start) {
+		case Version12:
+			return parse12(decoder, start)
+		case Version20:
+			return parse20(decoder, start)
+		default:
+			return nil, UnsupportedVersion
+		}
+	}
+}
+
+func version(start xml.StartElement) string {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "version" {
+			// 2.1 and 2.2 only add modules to the 2.0 core
+			if strings.HasPrefix(attr.Value, "2.") {
+				return Version20
+			}
+			return attr.Value
+		}
+	}
+
+	return ""
+}
+
+// Write writes the document as XLIFF of its Version.
+func (d *Document) Write(w io.Writer) error {
+	var root interface{}
+
+	switch d.Version {
+	case "", Version12:
+		root = d.to12()
+	case Version20:
+		root = d.to20()
+	default:
+		return UnsupportedVersion
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(root)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// UploadRequest returns a request uploading the document to a project,
+// in the source language of its first file.
+func (d *Document) UploadRequest(title, projectId string) (lingotek.UploadRequest, error) {
+	var buf bytes.Buffer
+	err := d.Write(&buf)
+	if err != nil {
+		return lingotek.UploadRequest{}, err
+	}
+
+	upload := lingotek.UploadRequest{
+		Title:     title,
+		ProjectId: projectId,
+		Format:    Format,
+		Charset:   "UTF-8",
+		Reader:    &buf,
+	}
+	if len(d.Files) > 0 {
+		upload.LocaleCode = d.Files[0].SourceLanguage
+	}
+
+	return upload, nil
+}
+
+// content is the raw XML of a <source> or <target>.
+type content struct {
+	State string `xml:"state,attr,omitempty"`
+	XML   string `xml:",innerxml"`
+}
+
+func contentOf(c *content) string {
+	if c == nil {
+		return ""
+	}
+
+	return c.XML
+}
+
+// defaultState is the state of a unit that doesn't give one.
+func defaultState(target string) State {
+	if target != "" {
+		return StateTranslated
+	}
+
+	return StateInitial
+}
@@ -0,0 +1,158 @@
+package xliff
+
+import "encoding/xml"
+
+const namespace12 = "urn:oasis:names:tc:xliff:document:1.2"
+
+type xliff12 struct {
+	XMLName xml.Name `xml:"xliff"`
+	Version string   `xml:"version,attr"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Files   []file12 `xml:"file"`
+}
+
+type file12 struct {
+	Original       string  `xml:"original,attr"`
+	SourceLanguage string  `xml:"source-language,attr"`
+	TargetLanguage string  `xml:"target-language,attr,omitempty"`
+	Datatype       string  `xml:"datatype,attr"`
+	Body           *node12 `xml:"body"`
+}
+
+// node12 decodes <body>, <group> and <trans-unit> elements, keeping the
+// order of units and groups.
+type node12 struct {
+	XMLName  xml.Name
+	Id       string   `xml:"id,attr"`
+	Resname  string   `xml:"resname,attr"`
+	Source   *content `xml:"source"`
+	Target   *content `xml:"target"`
+	Notes    []string `xml:"note"`
+	Children []node12 `xml:",any"`
+}
+
+type unit12 struct {
+	XMLName xml.Name `xml:"trans-unit"`
+	Id      string   `xml:"id,attr"`
+	Resname string   `xml:"resname,attr,omitempty"`
+	Source  content  `xml:"source"`
+	Target  *content `xml:"target"`
+	Notes   []string `xml:"note"`
+}
+
+type body12 struct {
+	Units []unit12 `xml:"trans-unit"`
+}
+
+type output12 struct {
+	XMLName xml.Name       `xml:"xliff"`
+	Version string         `xml:"version,attr"`
+	Xmlns   string         `xml:"xmlns,attr"`
+	Files   []fileOutput12 `xml:"file"`
+}
+
+type fileOutput12 struct {
+	Original       string `xml:"original,attr"`
+	SourceLanguage string `xml:"source-language,attr"`
+	TargetLanguage string `xml:"target-language,attr,omitempty"`
+	Datatype       string `xml:"datatype,attr"`
+	Body           body12 `xml:"body"`
+}
+
+var states12 = map[string]State{
+	"new":                      StateInitial,
+	"needs-translation":        StateInitial,
+	"needs-adaptation":         StateInitial,
+	"needs-l10n":               StateInitial,
+	"translated":               StateTranslated,
+	"needs-review-translation": StateTranslated,
+	"needs-review-adaptation":  StateTranslated,
+	"needs-review-l10n":        StateTranslated,
+	"signed-off":               StateReviewed,
+	"final":                    StateFinal,
+}
+
+var stateNames12 = map[State]string{
+	StateInitial:    "new",
+	StateTranslated: "translated",
+	StateReviewed:   "signed-off",
+	StateFinal:      "final",
+}
+
+func parse12(decoder *xml.Decoder, start xml.StartElement) (*Document, error) {
+	var root xliff12
+	err := decoder.DecodeElement(&root, &start)
+	if err != nil {
+		return nil, err
+	}
+
+	document := Document{Version: Version12}
+	for _, f := range root.Files {
+		file := File{
+			Original:       f.Original,
+			SourceLanguage: f.SourceLanguage,
+			TargetLanguage: f.TargetLanguage,
+		}
+		if f.Body != nil {
+			file.Units = f.Body.units(nil)
+		}
+		document.Files = append(document.Files, file)
+	}
+
+	return &document, nil
+}
+
+// units collects the trans-units below n, descending into groups.
+func (n *node12) units(units []Unit) []Unit {
+	for i := range n.Children {
+		child := &n.Children[i]
+
+		switch child.XMLName.Local {
+		case "group":
+			units = child.units(units)
+		case "trans-unit":
+			target := contentOf(child.Target)
+			state, ok := State(""), false
+			if child.Target != nil {
+				state, ok = states12[child.Target.State]
+			}
+			if !ok {
+				state = defaultState(target)
+			}
+
+			units = append(units, Unit{
+				Id:     child.Id,
+				Name:   child.Resname,
+				Source: contentOf(child.Source),
+				Target: target,
+				Notes:  child.Notes,
+				State:  state,
+			})
+		}
+	}
+
+	return units
+}
+
+func (d *Document) to12() *output12 {
+	root := output12{Version: Version12, Xmlns: namespace12}
+	root.Files = make([]fileOutput12, len(d.Files))
+
+	for i, file := range d.Files {
+		out := &root.Files[i]
+		out.Original = file.Original
+		out.SourceLanguage = file.SourceLanguage
+		out.TargetLanguage = file.TargetLanguage
+		out.Datatype = "plaintext"
+
+		for _, unit := range file.Units {
+			u := unit12{Id: unit.Id, Resname: unit.Name, Source: content{XML: unit.Source}, Notes: unit.Notes}
+			if unit.Target != "" || unit.State != "" && unit.State != StateInitial {
+				u.Target = &content{State: stateNames12[unit.State], XML: unit.Target}
+			}
+			out.Body.Units = append(out.Body.Units, u)
+		}
+	}
+
+	return &root
+}
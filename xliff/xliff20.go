@@ -0,0 +1,166 @@
+package xliff
+
+import (
+	"encoding/xml"
+	"strconv"
+)
+
+const namespace20 = "urn:oasis:names:tc:xliff:document:2.0"
+
+type xliff20 struct {
+	XMLName xml.Name `xml:"xliff"`
+	SrcLang string   `xml:"srcLang,attr"`
+	TrgLang string   `xml:"trgLang,attr"`
+	Files   []node20 `xml:"file"`
+}
+
+// node20 decodes <file>, <group>, <unit>, <segment> and <ignorable>
+// elements, keeping the order of their children.
+type node20 struct {
+	XMLName  xml.Name
+	Id       string   `xml:"id,attr"`
+	Name     string   `xml:"name,attr"`
+	Original string   `xml:"original,attr"`
+	State    string   `xml:"state,attr"`
+	Notes    []string `xml:"notes>note"`
+	Source   *content `xml:"source"`
+	Target   *content `xml:"target"`
+	Children []node20 `xml:",any"`
+}
+
+type output20 struct {
+	XMLName xml.Name       `xml:"xliff"`
+	Version string         `xml:"version,attr"`
+	Xmlns   string         `xml:"xmlns,attr"`
+	SrcLang string         `xml:"srcLang,attr"`
+	TrgLang string         `xml:"trgLang,attr,omitempty"`
+	Files   []fileOutput20 `xml:"file"`
+}
+
+type fileOutput20 struct {
+	Id       string         `xml:"id,attr"`
+	Original string         `xml:"original,attr,omitempty"`
+	Units    []unitOutput20 `xml:"unit"`
+}
+
+type unitOutput20 struct {
+	Id      string    `xml:"id,attr"`
+	Name    string    `xml:"name,attr,omitempty"`
+	Notes   []string  `xml:"notes>note"`
+	Segment segment20 `xml:"segment"`
+}
+
+type segment20 struct {
+	State  string   `xml:"state,attr,omitempty"`
+	Source content  `xml:"source"`
+	Target *content `xml:"target"`
+}
+
+func parse20(decoder *xml.Decoder, start xml.StartElement) (*Document, error) {
+	var root xliff20
+	err := decoder.DecodeElement(&root, &start)
+	if err != nil {
+		return nil, err
+	}
+
+	document := Document{Version: Version20}
+	for i := range root.Files {
+		f := &root.Files[i]
+		document.Files = append(document.Files, File{
+			Id:             f.Id,
+			Original:       f.Original,
+			SourceLanguage: root.SrcLang,
+			TargetLanguage: root.TrgLang,
+			Units:          f.units(nil),
+		})
+	}
+
+	return &document, nil
+}
+
+// units collects the units below n, descending into groups.
+func (n *node20) units(units []Unit) []Unit {
+	for i := range n.Children {
+		child := &n.Children[i]
+
+		switch child.XMLName.Local {
+		case "group":
+			units = child.units(units)
+		case "unit":
+			units = append(units, child.unit())
+		}
+	}
+
+	return units
+}
+
+// unit joins the segments and ignorables of a unit.
+func (n *node20) unit() Unit {
+	unit := Unit{Id: n.Id, Name: n.Name, Notes: n.Notes}
+	translated := false
+
+	for i := range n.Children {
+		child := &n.Children[i]
+
+		switch child.XMLName.Local {
+		case "segment":
+			state := State(child.State)
+			if _, ok := stateOrder[state]; !ok {
+				state = defaultState(contentOf(child.Target))
+			}
+			if unit.State == "" || stateOrder[state] < stateOrder[unit.State] {
+				unit.State = state
+			}
+
+			unit.Source += contentOf(child.Source)
+			unit.Target += contentOf(child.Target)
+			translated = translated || child.Target != nil
+		case "ignorable":
+			unit.Source += contentOf(child.Source)
+			if child.Target != nil {
+				unit.Target += contentOf(child.Target)
+			} else {
+				unit.Target += contentOf(child.Source)
+			}
+		}
+	}
+
+	// Ignorables alone don't make a translation
+	if !translated {
+		unit.Target = ""
+	}
+	if unit.State == "" {
+		unit.State = StateInitial
+	}
+
+	return unit
+}
+
+func (d *Document) to20() *output20 {
+	root := output20{Version: Version20, Xmlns: namespace20}
+	if len(d.Files) > 0 {
+		root.SrcLang = d.Files[0].SourceLanguage
+		root.TrgLang = d.Files[0].TargetLanguage
+	}
+
+	for i, file := range d.Files {
+		out := fileOutput20{Id: file.Id, Original: file.Original}
+		if out.Id == "" {
+			out.Id = "f" + strconv.Itoa(i+1)
+		}
+
+		for _, unit := range file.Units {
+			u := unitOutput20{Id: unit.Id, Name: unit.Name, Notes: unit.Notes}
+			u.Segment.Source.XML = unit.Source
+			if unit.Target != "" || unit.State != "" && unit.State != StateInitial {
+				u.Segment.State = string(unit.State)
+				u.Segment.Target = &content{XML: unit.Target}
+			}
+			out.Units = append(out.Units, u)
+		}
+
+		root.Files = append(root.Files, out)
+	}
+
+	return &root
+}
@@ -0,0 +1,150 @@
+package xliff
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func parseFile(t *testing.T, path string) *Document {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	document, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return document
+}
+
+func roundTrip(t *testing.T, document *Document) *Document {
+	var buf bytes.Buffer
+	if err := document.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return parsed
+}
+
+func TestParse12(t *testing.T) {
+	document := parseFile(t, "../test_data/xliff12.xlf")
+
+	if document.Version != Version12 || len(document.Files) != 1 {
+		t.Fatalf("Unexpected document %+v", document)
+	}
+
+	file := document.Files[0]
+	if file.Original != "messages.json" || file.SourceLanguage != "en-US" || file.TargetLanguage != "es-ES" {
+		t.Errorf("Unexpected file %+v", file)
+	}
+
+	expected := []Unit{
+		{Id: "1", Name: "greeting", Source: `Hello, <g id="b">world</g>!`, Target: `¡Hola, <g id="b">mundo</g>!`, Notes: []string{"Shown on the home page"}, State: StateTranslated},
+		{Id: "2", Name: "errors.missing", Source: "File &lt;%s&gt; is missing", Target: "Falta el archivo &lt;%s&gt;", State: StateTranslated},
+		{Id: "3", Name: "errors.denied", Source: "Access denied", State: StateInitial},
+		{Id: "4", Name: "farewell", Source: "Goodbye", Target: "Adiós", State: StateReviewed},
+	}
+	if !reflect.DeepEqual(file.Units, expected) {
+		t.Errorf("Expected units\n%+v\ngot\n%+v", expected, file.Units)
+	}
+
+	if text := file.Unit("2").SourceText(); text != "File <%s> is missing" {
+		t.Errorf("Unexpected source text %q", text)
+	}
+	if text := file.Unit("1").TargetText(); text != "¡Hola, mundo!" {
+		t.Errorf("Unexpected target text %q", text)
+	}
+
+	if parsed := roundTrip(t, document); !reflect.DeepEqual(parsed, document) {
+		t.Errorf("Expected round trip to keep\n%+v\ngot\n%+v", document, parsed)
+	}
+}
+
+func TestParse20(t *testing.T) {
+	document := parseFile(t, "../test_data/xliff20.xlf")
+
+	if document.Version != Version20 || len(document.Files) != 1 {
+		t.Fatalf("Unexpected document %+v", document)
+	}
+
+	file := document.Files[0]
+	if file.Id != "f1" || file.SourceLanguage != "en-US" || file.TargetLanguage != "fr-FR" {
+		t.Errorf("Unexpected file %+v", file)
+	}
+
+	expected := []Unit{
+		{Id: "greeting", Name: "greeting", Source: `Hello, <pc id="1">world</pc>!`, Target: `Bonjour, <pc id="1">le monde</pc> !`, Notes: []string{"Shown on the home page"}, State: StateFinal},
+		{Id: "errors.missing", Source: "File is missing. Try again.", Target: "Le fichier est manquant. ", State: StateInitial},
+		{Id: "empty", Source: "Untranslated", State: StateInitial},
+	}
+	if !reflect.DeepEqual(file.Units, expected) {
+		t.Errorf("Expected units\n%+v\ngot\n%+v", expected, file.Units)
+	}
+
+	if parsed := roundTrip(t, document); !reflect.DeepEqual(parsed, document) {
+		t.Errorf("Expected round trip to keep\n%+v\ngot\n%+v", document, parsed)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	for _, version := range []string{Version12, Version20} {
+		document := NewDocument(version, "app.json", "en-US", "de-DE")
+		document.Files[0].Add("title", "Fish & <Chips>")
+		document.Files[0].Add("body", "Tap to start").Notes = []string{"Button label"}
+
+		parsed := roundTrip(t, document)
+		if !reflect.DeepEqual(parsed, document) {
+			t.Errorf("%s: expected\n%+v\ngot\n%+v", version, document, parsed)
+		}
+
+		if text := parsed.Files[0].Unit("title").SourceText(); text != "Fish & <Chips>" {
+			t.Errorf("%s: unexpected source text %q", version, text)
+		}
+	}
+}
+
+func TestUploadRequest(t *testing.T) {
+	document := NewDocument(Version20, "app.json", "en-US", "")
+	document.Files[0].Add("hello", "Hello")
+
+	upload, err := document.UploadRequest("app.xlf", "project")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if upload.Format != Format || upload.LocaleCode != "en-US" || upload.ProjectId != "project" {
+		t.Errorf("Unexpected upload %+v", upload)
+	}
+
+	content, err := io.ReadAll(upload.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(content, []byte(`<source>Hello</source>`)) {
+		t.Errorf("Unexpected content %s", content)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := Parse(strings.NewReader(`<xliff version="3.0"></xliff>`))
+	if err != UnsupportedVersion {
+		t.Errorf("Expected UnsupportedVersion, got %v", err)
+	}
+
+	_, err = Parse(strings.NewReader(`<html></html>`))
+	if err != NotXLIFF {
+		t.Errorf("Expected NotXLIFF, got %v", err)
+	}
+}